@@ -1,26 +1,49 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pborman/getopt/v2"
 	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
 	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/log"
 	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/parse"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/screen"
 )
 
 type Args struct {
 	InputFile             string
+	InputFiles            []string
 	OutputFile            string
 	Stdin                 bool
 	Stdout                bool
 	FlipHorizontal        bool
 	FlipVertical          bool
+	Rotate                int
+	Crop                  []int
+	Scale                 int
+	Downscale             string
+	Overlay               string
+	OverlayAt             []int
+	Transparency          convert.Transparency
+	FromImage             bool
+	ImageWidth            int
+	Dither                bool
+	Tile                  string
+	TileOptions           convert.TileOptions
 	Sanitise              bool
 	Optimise              bool
+	CarryState            bool
 	Justify               bool
 	Help                  bool
 	Display               bool
@@ -31,6 +54,25 @@ type Args struct {
 	DisplaySAUCEInfo      bool
 	DisplaySAUCEInfoJSON  bool
 	DetectEncoding        bool
+	Screen                bool
+	Play                  bool
+	FrameDelay            time.Duration
+	FramesDir             string
+	SetTitle              *string
+	SetAuthor             *string
+	SetGroup              *string
+	SetDate               *string
+	SetFont               *string
+	To                    string
+	Colours               string
+	ColourMatch           string
+	Palette               string
+	TrimRight             bool
+}
+
+// EditsSAUCE returns true if any of the SAUCE editing options were given
+func (a Args) EditsSAUCE() bool {
+	return a.SetTitle != nil || a.SetAuthor != nil || a.SetGroup != nil || a.SetDate != nil || a.SetFont != nil
 }
 
 // Check DEBUG mode, enables debug logging
@@ -44,44 +86,99 @@ func main() {
 
 	help := getopt.BoolLong("help", 'h', "display this help message")
 
-	inputFile := getopt.StringLong("input", 'i', "", "Input file path (default: stdin)")
+	var inputFiles inputFileList
+	getopt.FlagLong(&inputFiles, "input", 'i', "Input file path (default: stdin), repeat to give more than one file for --tile")
 	outputFile := getopt.StringLong("output", 'o', "", "Output file path (default: stdout)")
 
 	flip := getopt.EnumLong("flip", 'f', []string{"h", "v", "h,v", "v,h"}, "", "Flip horizontally (h), vertically (v), or both (h,v or v,h)")
+	rotate := getopt.EnumLong("rotate", 'r', []string{"90", "180", "270"}, "", "Rotate clockwise by 90, 180 or 270 degrees")
+	crop := getopt.StringLong("crop", 0, "", "Crop to a rectangle of w columns by h lines from column x of line y (from 0), given as x,y,w,h")
+	scale := getopt.IntLong("scale", 0, 0, "Enlarge by repeating each cell N times horizontally & vertically")
+	downscale := getopt.EnumLong("downscale", 0, []string{"half", "quadrant"}, "", "Shrink by combining 1x2 cells into half blocks (half), or 2x2 cells into quadrant blocks (quadrant)")
+	overlay := getopt.StringLong("overlay", 0, "", "Place the art from another file on top of the input (see --overlay-at & --transparent-*)")
+	overlayAt := getopt.StringLong("overlay-at", 0, "0,0", "Position of the overlay, given as x,y (column & line, from 0)")
+	transparentChar := getopt.StringLong("transparent-char", 0, "", "Character in the overlay that shows the input below, e.g. \" \"")
+	transparentBG := getopt.IntLong("transparent-bg", 0, -1, "Background colour (0-255) in the overlay that shows the input below, where no background counts as 0")
+	tile := getopt.EnumLong("tile", 0, []string{"row", "column", "grid"}, "", "Tile the input files (given with -i) in a row, a column, or a grid (see --tile-*)")
+	tileColumns := getopt.IntLong("tile-columns", 0, 0, "Number of images in each row of a grid (default: square)")
+	tilePadding := getopt.IntLong("tile-padding", 0, 0, "Blank columns & lines on each side of the gap between tiles")
+	tileSeparator := getopt.StringLong("tile-separator", 0, "", "Separator drawn between columns of tiles, e.g. \"│\"")
+	tileRowSeparator := getopt.StringLong("tile-row-separator", 0, "", "Separator repeated across the width between rows of tiles, e.g. \"─\"")
+	tileHAlign := getopt.EnumLong("tile-halign", 0, []string{"left", "centre", "right"}, "left", "Horizontal alignment of images in their tile, left, centre or right")
+	tileVAlign := getopt.EnumLong("tile-valign", 0, []string{"top", "middle", "bottom"}, "top", "Vertical alignment of images in their tile, top, middle or bottom")
 	getopt.BoolLong("sanitise", 's', "Sanitise ANSI lines, ensuring that each line ends with a reset code")
 	justify := getopt.BoolLong("justify", 'j', "Justify lines to the same length (sanitise mode only)")
-	optimise := getopt.BoolLong("optimise", 'O', "Optimise ANSI tokens to merge redundant color codes")
+	optimise := getopt.BoolLong("optimise", 'O', "Optimise ANSI tokens to merge redundant color codes, writing the shortest codes for each colour change")
+	carryState := getopt.BoolLong("carry-state", 0, "Carry colours over to the next line instead of resetting at the end of each line (optimise mode only)")
 	display := getopt.BoolLong("display", 'd', "Display original and flipped side-by-side in terminal")
 
 	convertAns := getopt.BoolLong("convert-ans", 'c', "Convert an ANSI .ans file (CP437 encoded) to UTF-8 ANSI")
 	displaySAUCE := getopt.BoolLong("display-sauce", 'S', "Display SAUCE metadata from input file (if present)")
 	displaySAUCEInfoJSON := getopt.BoolLong("display-sauce-json", 0, "Display SAUCE metadata from input file in JSON format (if present)")
 	detectEncoding := getopt.BoolLong("detect-encoding", 'e', "Detect if input file is CP437 or ISO-8859-1 encoded")
+	useScreen := getopt.BoolLong("screen", 'V', "Replay cursor movement & clear codes through a virtual terminal screen before processing")
+	play := getopt.BoolLong("play", 0, "Play an ANSImation in the terminal, one frame (split at cursor home & clear screen codes) every --frame-delay")
+	frameDelay := getopt.DurationLong("frame-delay", 0, 100*time.Millisecond, "Delay between the frames of --play & --to gif, e.g. 100ms")
+	framesDir := getopt.StringLong("frames-dir", 0, "", "Split an ANSImation into one ANSI file per frame (frame-0001.ans, ...) in a directory")
+
+	setTitle := getopt.StringLong("set-title", 0, "", "Set the SAUCE title (max 35 chars), leaving the file data untouched")
+	setAuthor := getopt.StringLong("set-author", 0, "", "Set the SAUCE author (max 20 chars), leaving the file data untouched")
+	setGroup := getopt.StringLong("set-group", 0, "", "Set the SAUCE group (max 20 chars), leaving the file data untouched")
+	setDate := getopt.StringLong("set-date", 0, "", "Set the SAUCE date (CCYYMMDD), leaving the file data untouched")
+	setFont := getopt.StringLong("set-font", 0, "", "Set the SAUCE font name, e.g. \"IBM VGA\" (max 22 chars), leaving the file data untouched")
+
+	to := getopt.EnumLong("to", 0, []string{"ansi", "html", "svg", "png", "bin", "pcboard", "tundra", "gif", "text"}, "ansi", "Output format, ansi, html, svg, png, gif, bin, pcboard, tundra or text (combine with --convert-ans for .ans, .bin & PCBoard files)")
+
+	trimRight := getopt.BoolLong("trim-right", 0, "Remove trailing spaces from each line of --to text")
+
+	fromImage := getopt.BoolLong("from-image", 0, "Convert a PNG, GIF or JPEG image to half block ANSI art, before any other processing (see --colours)")
+	imageWidth := getopt.IntLong("image-width", 0, 0, "Width in columns of art converted from an image (default: the image width)")
+	dither := getopt.BoolLong("dither", 0, "Dither colours when converting from an image with fewer --colours (Floyd-Steinberg)")
+
+	colours := getopt.EnumLong("colours", 0, []string{"truecolor", "256", "16", "8", "mono"}, "", "Convert the output colours to truecolor (using --palette), or reduce them to 256, 16, 8 or mono (no colour)")
+	colourMatch := getopt.EnumLong("colour-match", 0, []string{"ciede2000", "euclidean"}, "ciede2000", "Nearest colour matching when reducing colours, ciede2000 or euclidean")
+	palette := getopt.StringLong("palette", 0, "vga", "Palette for --colours truecolor: vga, xp, xterm, solarized, or the path to a JSON palette file")
 
 	displaySep := getopt.StringLong("display-separator", 0, " ", "Separator string between original and flipped when displaying")
 	displaySepWidth := getopt.IntLong("display-separator-width", 0, 1, "Width of separator between original and flipped when displaying")
 	displaySwapped := getopt.BoolLong("display-swapped", 'x', "When displaying, reverse the order of original and flipped")
 
-	getopt.Lookup("convert-ans").SetGroup("operation")
-	getopt.Lookup("flip").SetGroup("operation")
-	getopt.Lookup("sanitise").SetGroup("operation")
-	getopt.Lookup("help").SetGroup("operation")
-	getopt.Lookup("optimise").SetGroup("operation")
-	getopt.Lookup("display-sauce").SetGroup("operation")
-	getopt.Lookup("detect-encoding").SetGroup("operation")
-	getopt.RequiredGroup("operation")
+	operations := []string{"convert-ans", "flip", "rotate", "crop", "scale", "downscale", "overlay", "tile", "sanitise", "help", "optimise", "display-sauce", "display-sauce-json", "detect-encoding", "play", "frames-dir"}
+	for _, name := range operations {
+		getopt.Lookup(name).SetGroup("operation")
+	}
 
 	getopt.Parse()
 
+	// the enum only allows 90, 180 & 270, so the only error is for a missing --rotate
+	rotation, _ := strconv.Atoi(*rotate)
+	tileOpts := tileOptions(
+		*tile, *tileColumns, len(inputFiles), *tilePadding, *tileSeparator, *tileRowSeparator, *tileHAlign, *tileVAlign,
+	)
+
 	args := Args{
-		InputFile:             *inputFile,
+		InputFile:             inputFiles.first(),
+		InputFiles:            inputFiles,
 		OutputFile:            *outputFile,
 		Stdin:                 !getopt.IsSet("input"),
 		Stdout:                !getopt.IsSet("output"),
 		FlipHorizontal:        strings.Contains(*flip, "h"),
 		FlipVertical:          strings.Contains(*flip, "v"),
+		Rotate:                rotation,
+		Crop:                  parseCoordinates("crop", *crop, "x,y,w,h"),
+		Scale:                 *scale,
+		Downscale:             *downscale,
+		Overlay:               *overlay,
+		OverlayAt:             parseCoordinates("overlay-at", *overlayAt, "x,y"),
+		Transparency:          transparency(*transparentChar, *transparentBG),
+		FromImage:             *fromImage,
+		ImageWidth:            *imageWidth,
+		Dither:                *dither,
+		Tile:                  *tile,
+		TileOptions:           tileOpts,
 		Sanitise:              getopt.IsSet("sanitise"),
 		Optimise:              *optimise,
+		CarryState:            *carryState,
 		Justify:               *justify,
 		Help:                  *help,
 		Display:               *display,
@@ -92,6 +189,34 @@ func main() {
 		DisplaySAUCEInfo:      *displaySAUCE,
 		DisplaySAUCEInfoJSON:  *displaySAUCEInfoJSON,
 		DetectEncoding:        *detectEncoding,
+		Screen:                *useScreen,
+		Play:                  *play,
+		FrameDelay:            *frameDelay,
+		FramesDir:             *framesDir,
+		SetTitle:              optionalString("set-title", setTitle),
+		SetAuthor:             optionalString("set-author", setAuthor),
+		SetGroup:              optionalString("set-group", setGroup),
+		SetDate:               optionalString("set-date", setDate),
+		SetFont:               optionalString("set-font", setFont),
+		To:                    *to,
+		Colours:               *colours,
+		ColourMatch:           *colourMatch,
+		Palette:               *palette,
+		TrimRight:             *trimRight,
+	}
+
+	// the SAUCE editing options can be combined with each other, so they can't be part of
+	// the (mutually exclusive) operation group, but they do count as an operation.
+	// --to, --from-image & --screen can be combined with an operation, or given on their own
+	hasOperation := slices.ContainsFunc(operations, func(name string) bool { return getopt.IsSet(name) })
+	if args.EditsSAUCE() == (hasOperation || getopt.IsSet("to") || args.FromImage || args.Screen) {
+		if args.EditsSAUCE() {
+			fmt.Fprintln(os.Stderr, "the --set-* options cannot be combined with any of:", strings.Join(append(operations, "to", "from-image", "screen"), ", "))
+		} else {
+			fmt.Fprintln(os.Stderr, "exactly one of the following options must be specified:", strings.Join(operations, ", "))
+		}
+		getopt.Usage()
+		os.Exit(1)
 	}
 
 	if args.Help {
@@ -107,19 +232,57 @@ func main() {
 		fmt.Printf("%s\n", encoding)
 		return
 	}
+	if args.EditsSAUCE() {
+		writeOutput(args, string(editSAUCE(args, raw, encoding)))
+		return
+	}
+	if args.FromImage {
+		// the rest of the processing works on the ANSI art
+		input = imageToANSI(args, raw)
+		raw, encoding = []byte(input), "utf-8"
+	}
 
 	sauce, fileData, err := convert.SAUCERecord(raw, encoding)
 	if err != nil {
 		log.DebugFprintf("\x1b[91mUnable to determine file info: \x1b[0m%v\n", err)
 		os.Exit(1)
 	}
+	fileData, font := decodeBinaryFormats(args, raw, sauce, fileData)
+	if font == nil && (args.To == "png" || args.To == "gif") {
+		font = sauceFont(sauce)
+	}
 	if args.DisplaySAUCEInfo {
 		fmt.Println(sauce.ToString())
 		return
 	}
+	if args.DisplaySAUCEInfoJSON {
+		sauceJSON, err := sauce.ToJSON()
+		if err != nil {
+			log.DebugFprintf("\x1b[91mUnable to convert SAUCE to JSON: \x1b[0m%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(sauceJSON)
+		return
+	}
 	log.DebugFprintln(sauce.ToString())
 
+	if args.Play || args.FramesDir != "" {
+		animate(args, fileData, sauce)
+		return
+	}
+	if args.Screen {
+		fileData = convert.BuildANSIString(screen.Render(fileData, screenWidth(sauce)), 0)
+	}
+
+	if args.To == "gif" {
+		writeOutput(args, exportGIF(args, fileData, sauce, font))
+		return
+	}
+
 	result := process(args, fileData, sauce)
+	if args.Colours != "" {
+		result = adjustColours(args, result)
+	}
 
 	if args.Display {
 		if args.FlipHorizontal {
@@ -127,54 +290,324 @@ func main() {
 		} else if args.FlipVertical {
 			displayAboveBelow(input, result, args)
 		}
+	} else if args.To != "ansi" {
+		writeOutput(args, export(args, result, sauce, font))
 	} else {
 		writeOutput(args, result)
 	}
 }
 
-// displaySideBySide prints the original and flipped result side-by-side, separated by a space
-func displaySideBySide(original, flipped string, args Args) {
-	origLines := strings.Split(convert.SanitiseUnicodeString(original, true), "\n")
-	flippedLines := strings.Split(flipped, "\n")
-	for i := 0; i < len(origLines) && i < len(flippedLines); i++ {
-		if !args.DisplaySwapped {
-			fmt.Printf(
-				"%s%s%s\n",
-				origLines[i],
-				strings.Repeat(args.DisplaySeparator, args.DisplaySeparatorWidth),
-				flippedLines[i],
-			)
-		} else {
-			fmt.Printf(
-				"%s%s%s\n",
-				flippedLines[i],
-				strings.Repeat(args.DisplaySeparator, args.DisplaySeparatorWidth),
-				origLines[i],
-			)
+// screenWidth returns the width that the virtual terminal screen wraps at, from the SAUCE record or 80 by default
+func screenWidth(sauce *convert.SAUCE) int {
+	if sauce.TInfo1.Value > 0 {
+		return int(sauce.TInfo1.Value)
+	}
+	return 80
+}
+
+// animate splits an ANSImation into frames (see screen.Frames), and plays them in the terminal (--play),
+// or writes each frame to a file in --frames-dir
+func animate(args Args, input string, sauce *convert.SAUCE) {
+	frames := screen.Frames(input, screenWidth(sauce))
+	if args.FramesDir != "" {
+		if err := os.MkdirAll(args.FramesDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to create frames directory: %v\n", err)
+			os.Exit(1)
+		}
+		for i, frame := range frames {
+			writeFile(filepath.Join(args.FramesDir, fmt.Sprintf("frame-%04d.ans", i+1)), convert.BuildANSIString(frame, 0))
+		}
+		return
+	}
+
+	// hide the cursor while playing, and clear the screen before each frame.
+	// The cursor is shown again if playback is interrupted (Ctrl-C)
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	go func() {
+		<-interrupted
+		fmt.Print("\x1b[0m\x1b[?25h\n")
+		os.Exit(130)
+	}()
+
+	fmt.Print("\x1b[?25l")
+	for i, frame := range frames {
+		if i > 0 {
+			time.Sleep(args.FrameDelay)
+		}
+		fmt.Print("\x1b[H\x1b[2J" + convert.BuildANSIString(frame, 0))
+	}
+	fmt.Print("\x1b[?25h")
+}
+
+// adjustColours converts the colours of the processed ANSI output to truecolor,
+// or downsamples them to the depth given by --colours
+func adjustColours(args Args, output string) string {
+	lines := convert.TokeniseANSIString(output)
+	if args.Colours == "truecolor" {
+		return convert.BuildANSIString(convert.UpsampleColours(lines, loadPalette(args.Palette)), 0)
+	}
+	return convert.BuildANSIString(convert.DownsampleColours(lines, colourDepths[args.Colours], colourMetric(args)), 0)
+}
+
+// colourDepths are the colour depths of the --colours option
+var colourDepths = map[string]convert.ColourDepth{
+	"truecolor": convert.DepthTrueColor,
+	"256":       convert.Depth256,
+	"16":        convert.Depth16,
+	"8":         convert.Depth8,
+	"mono":      convert.DepthMono,
+}
+
+// colourMetric returns the nearest colour matching given by --colour-match
+func colourMetric(args Args) convert.ColourMetric {
+	if args.ColourMatch == "euclidean" {
+		return convert.MetricEuclidean
+	}
+	return convert.MetricCIEDE2000
+}
+
+// imageToANSI converts image data to half block ANSI art, using the --colours depth & --colour-match metric
+func imageToANSI(args Args, data []byte) string {
+	img, err := convert.DecodeImage(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to decode image: %v\n", err)
+		os.Exit(1)
+	}
+	return convert.BuildANSIString(convert.ImageToTokens(img, convert.ImageOptions{
+		Width:  args.ImageWidth,
+		Depth:  colourDepths[args.Colours],
+		Metric: colourMetric(args),
+		Dither: args.Dither,
+	}), 0)
+}
+
+// loadPalette returns a built-in palette by name, or reads a palette from a JSON file
+func loadPalette(name string) convert.Palette {
+	if palette, ok := convert.Palettes[name]; ok {
+		return palette
+	}
+	data, err := os.ReadFile(name)
+	if err == nil {
+		var palette convert.Palette
+		if palette, err = convert.ParsePaletteJSON(data); err == nil {
+			return palette
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unable to load palette %q: %v\n", name, err)
+	os.Exit(1)
+	return convert.Palette{}
+}
+
+// export renders the processed ANSI output in the format given by --to.
+// The font is used by the raster formats, and is chosen from the SAUCE record if nil.
+func export(args Args, output string, sauce *convert.SAUCE, font *render.Font) string {
+	lines := convert.TokeniseANSIString(output)
+	switch args.To {
+	case "html":
+		return render.HTML(lines, sauce)
+	case "svg":
+		return render.SVG(lines, sauce)
+	case "text":
+		return render.Text(lines, args.TrimRight)
+	case "bin":
+		return exportBinaryText(lines, sauce)
+	case "pcboard":
+		// PCBoard files have nowhere to record iCE colour, so bit 7 of each attribute follows the input's
+		// SAUCE flag, as it would be displayed (--to bin writes iCE colour & sets the flag in its own record)
+		return string(convert.EncodePCBoard(lines, sauce.HasNonBlinkMode()))
+	case "tundra":
+		return exportTundra(lines, sauce)
+	case "png":
+		data, err := render.PNG(lines, sauce, font)
+		if err != nil {
+			log.DebugFprintf("\x1b[91mUnable to render PNG: \x1b[0m%v\n", err)
+			os.Exit(1)
+		}
+		return string(data)
+	default:
+		return output
+	}
+}
+
+// exportGIF renders the input as an animated GIF. ANSI files are split into the frames of an ANSImation
+// (see screen.Frames), and each frame is processed in the same way as a still image.
+func exportGIF(args Args, input string, sauce *convert.SAUCE, font *render.Font) string {
+	inputs := []string{input}
+	isANSI := sauce.FileType == convert.FileTypeCharacterANSI || sauce.FileType == convert.FileTypeCharacterANSIMation
+	if sauce.DataType == convert.DataTypeCharacter && isANSI {
+		inputs = inputs[:0]
+		for _, frame := range screen.Frames(input, screenWidth(sauce)) {
+			inputs = append(inputs, convert.BuildANSIString(frame, 0))
+		}
+	}
+
+	frames := make([][][]convert.ANSILineToken, 0, len(inputs))
+	for _, frame := range inputs {
+		// each frame has the lines of the screen, rather than the whole file
+		frameSauce := *sauce
+		frameSauce.TInfo2.Value = uint16(strings.Count(frame, "\n"))
+		result := process(args, frame, &frameSauce)
+		if args.Colours != "" {
+			result = adjustColours(args, result)
 		}
+		// the DOS rules match how the frames are drawn, and keep blink codes so that blinking text can alternate
+		frames = append(frames, convert.TokeniseDOSANSIString(result, sauce.HasNonBlinkMode()))
+	}
+
+	data, err := render.GIF(frames, sauce, font, args.FrameDelay)
+	if err != nil {
+		log.DebugFprintf("\x1b[91mUnable to render GIF: \x1b[0m%v\n", err)
+		os.Exit(1)
+	}
+	return string(data)
+}
+
+// exportBinaryText encodes the output as Binary Text (with iCE colour), with a SAUCE record that
+// keeps the metadata of the input
+func exportBinaryText(lines [][]convert.ANSILineToken, sauce *convert.SAUCE) string {
+	data, width, err := convert.EncodeBinaryText(lines, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	record := *sauce
+	record.DataType, record.FileType = convert.DataTypeBinaryText, byte(width/2)
+	record.TInfo1, record.TInfo2 = convert.TInfoField{}, convert.TInfoField{}
+	record.TFlags |= convert.ANSiFlagNonBlinkMode
+	return string(convert.WriteSAUCE(data, &record))
 }
 
+// exportTundra encodes the output as TundraDraw, with a SAUCE record that keeps the metadata of the input
+func exportTundra(lines [][]convert.ANSILineToken, sauce *convert.SAUCE) string {
+	data, err := convert.EncodeTundra(lines, sauce.HasNonBlinkMode())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	record := *sauce
+	record.DataType, record.FileType = convert.DataTypeCharacter, convert.FileTypeCharacterTundraDraw
+	record.TInfo1 = convert.TInfoField{Name: convert.TInfoNameCharacterWidth, Value: convert.TundraWidth}
+	record.TInfo2 = convert.TInfoField{Name: convert.TInfoNameNumberOfLines, Value: uint16(len(lines))}
+	return string(convert.WriteSAUCE(data, &record))
+}
+
+// sauceFont returns the font that the raster formats use for the SAUCE font name, warning when it isn't built in
+func sauceFont(sauce *convert.SAUCE) *render.Font {
+	font, err := render.FontForSAUCE(sauce)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	}
+	return font
+}
+
+// decodeBinaryFormats detects the Binary Text, XBin & TundraDraw formats (where the SAUCE record is optional),
+// returning the file data decoded as CP437, and the font embedded in an XBin file (or nil).
+// Other file data is returned unchanged. Files without a SAUCE record are detected as Avatar from the .avt extension,
+// or as PCBoard if they have @X colour codes.
+func decodeBinaryFormats(args Args, raw []byte, sauce *convert.SAUCE, fileData string) (string, *render.Font) {
+	data := convert.StripSAUCE(raw)
+	if bytes.HasPrefix(data, []byte(convert.XBinID)) {
+		sauce.DataType, sauce.FileType = convert.DataTypeXBin, 0
+	} else if bytes.HasPrefix(data, []byte(convert.TundraID)) {
+		sauce.DataType, sauce.FileType = convert.DataTypeCharacter, convert.FileTypeCharacterTundraDraw
+	} else if !args.Stdin && len(data) == len(raw) && strings.EqualFold(filepath.Ext(args.InputFile), ".bin") {
+		sauce.DataType, sauce.FileType = convert.DataTypeBinaryText, 0
+	}
+	tundra := sauce.DataType == convert.DataTypeCharacter && sauce.FileType == convert.FileTypeCharacterTundraDraw
+	if sauce.DataType != convert.DataTypeBinaryText && sauce.DataType != convert.DataTypeXBin && !tundra {
+		if sauce.IsANSIFile() && len(data) == len(raw) {
+			if !args.Stdin && strings.EqualFold(filepath.Ext(args.InputFile), ".avt") {
+				// the size is calculated from the raw text, which is meaningless for Avatar
+				sauce.FileType = convert.FileTypeCharacterAvatar
+				sauce.TInfo1, sauce.TInfo2 = convert.TInfoField{}, convert.TInfoField{}
+			} else if convert.HasPCBoardCodes(fileData) {
+				sauce.FileType = convert.FileTypeCharacterPCBoard
+			}
+		}
+		return fileData, nil
+	}
+	// binary formats are always CP437, as their attribute bytes make the encoding impossible to detect
+	sauce.TInfo1, sauce.TInfo2 = convert.TInfoField{}, convert.TInfoField{}
+	fileData, err := parse.DecodeFileContents(data, "cp437")
+	if err != nil {
+		log.DebugFprintf("\x1b[91mUnable to decode binary file: \x1b[0m%v\n", err)
+		os.Exit(1)
+	}
+
+	var font *render.Font
+	if sauce.DataType == convert.DataTypeXBin {
+		if xbin, err := convert.ParseXBin(data); err == nil {
+			font = render.XBinFont(xbin)
+			if xbin.HasNonBlinkMode() {
+				sauce.TFlags |= convert.ANSiFlagNonBlinkMode
+			}
+		}
+	}
+	return fileData, font
+}
+
+// displaySideBySide prints the original and flipped result side-by-side, separated by the display separator
+func displaySideBySide(original, flipped string, args Args) {
+	display(original, flipped, args, convert.TileOptions{
+		Separator: strings.Repeat(args.DisplaySeparator, args.DisplaySeparatorWidth),
+	})
+}
+
+// displayAboveBelow prints the original above the flipped result, separated by a line of the display separator
 func displayAboveBelow(original, flipped string, args Args) {
-	sep := strings.Repeat(args.DisplaySeparator, args.DisplaySeparatorWidth)
-	if sep != "" {
-		sep += "\n"
-	}
-	if !args.DisplaySwapped {
-		fmt.Printf(
-			"%s%s%s",
-			convert.SanitiseUnicodeString(original, true),
-			sep,
-			flipped,
-		)
-	} else {
-		fmt.Printf(
-			"%s%s%s",
-			flipped,
-			sep,
-			convert.SanitiseUnicodeString(original, true),
-		)
+	display(original, flipped, args, convert.TileOptions{
+		Columns:      1,
+		RowSeparator: strings.Repeat(args.DisplaySeparator, args.DisplaySeparatorWidth),
+	})
+}
+
+// display tiles the (sanitised) original and flipped result, swapping them if --display-swapped was given
+func display(original, flipped string, args Args, opts convert.TileOptions) {
+	original = convert.SanitiseUnicodeString(original, true)
+	images := [][][]convert.ANSILineToken{convert.TokeniseANSIString(original), convert.TokeniseANSIString(flipped)}
+	if args.DisplaySwapped {
+		slices.Reverse(images)
+	}
+	fmt.Print(convert.BuildANSIString(convert.Tile(images, opts), 0))
+}
+
+// optionalString returns the value of a string option, or nil if the option was not given
+func optionalString(name string, value *string) *string {
+	if getopt.IsSet(name) {
+		return value
+	}
+	return nil
+}
+
+// editSAUCE applies the --set-* options to the SAUCE record of the raw file data,
+// creating a new record if there wasn't one. The file data itself is left untouched.
+func editSAUCE(args Args, raw []byte, encoding string) []byte {
+	sauce, _, err := convert.SAUCERecord(raw, encoding)
+	if err != nil {
+		log.DebugFprintf("\x1b[91mUnable to determine file info: \x1b[0m%v\n", err)
+		os.Exit(1)
+	}
+	if args.SetTitle != nil {
+		sauce.Title = *args.SetTitle
 	}
+	if args.SetAuthor != nil {
+		sauce.Author = *args.SetAuthor
+	}
+	if args.SetGroup != nil {
+		sauce.Group = *args.SetGroup
+	}
+	if args.SetDate != nil {
+		if err := sauce.SetDate(*args.SetDate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if args.SetFont != nil {
+		sauce.TInfoS = *args.SetFont
+	}
+	return convert.WriteSAUCE(raw, sauce)
 }
 
 func readInput(args Args) (string, string, []byte) {
@@ -208,14 +641,41 @@ func process(args Args, input string, sauce *convert.SAUCE) string {
 	if args.Optimise {
 		tokenized := convert.TokeniseANSIString(input)
 		optimised := convert.OptimiseANSITokens(tokenized)
-		return convert.BuildANSIString(optimised, 0)
+		return convert.BuildMinimalANSIString(optimised, 0, args.CarryState)
 	}
 	if args.Sanitise {
 		return convert.SanitiseUnicodeString(input, args.Justify)
 	}
+	if args.Rotate != 0 {
+		return runRotate(input, args)
+	}
+	if args.Crop != nil {
+		return runCrop(input, args)
+	}
+	if getopt.IsSet("scale") {
+		return runScale(input, args)
+	}
+	if args.Downscale != "" {
+		return runDownscale(input, args)
+	}
+	if args.Overlay != "" {
+		return runOverlay(input, args)
+	}
+	if args.Tile != "" {
+		return runTile(input, args)
+	}
 	return runFlip(input, args)
 }
 
+func runRotate(input string, args Args) string {
+	rotated, err := convert.Rotate(convert.TokeniseANSIString(input), args.Rotate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return convert.BuildANSIString(rotated, 0)
+}
+
 func runFlip(input string, args Args) string {
 	tokenized := convert.TokeniseANSIString(input)
 	if args.FlipHorizontal {
@@ -227,6 +687,142 @@ func runFlip(input string, args Args) string {
 	return convert.BuildANSIString(tokenized, 0)
 }
 
+func runCrop(input string, args Args) string {
+	x, y, w, h := args.Crop[0], args.Crop[1], args.Crop[2], args.Crop[3]
+	cropped, err := convert.Crop(convert.TokeniseANSIString(input), x, y, w, h)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return convert.BuildANSIString(cropped, 0)
+}
+
+func runScale(input string, args Args) string {
+	scaled, err := convert.Scale(convert.TokeniseANSIString(input), args.Scale)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return convert.BuildANSIString(scaled, 0)
+}
+
+func runDownscale(input string, args Args) string {
+	mode := convert.DownscaleHalfBlocks
+	if args.Downscale == "quadrant" {
+		mode = convert.DownscaleQuadrants
+	}
+	return convert.BuildANSIString(convert.Downscale(convert.TokeniseANSIString(input), mode), 0)
+}
+
+func runOverlay(input string, args Args) string {
+	overlay := convert.TokeniseANSIString(readFile(args.Overlay))
+	x, y := args.OverlayAt[0], args.OverlayAt[1]
+	return convert.BuildANSIString(convert.Compose(convert.TokeniseANSIString(input), overlay, x, y, args.Transparency), 0)
+}
+
+func runTile(input string, args Args) string {
+	images := [][][]convert.ANSILineToken{convert.TokeniseANSIString(input)}
+	for _, path := range args.InputFiles[min(1, len(args.InputFiles)):] {
+		images = append(images, convert.TokeniseANSIString(readFile(path)))
+	}
+	return convert.BuildANSIString(convert.Tile(images, args.TileOptions), 0)
+}
+
+// tileOptions returns the layout given by --tile & the --tile-* options
+func tileOptions(layout string, columns, images, padding int, separator, rowSeparator, hAlign, vAlign string) convert.TileOptions {
+	switch layout {
+	case "row":
+		columns = 0
+	case "column":
+		columns = 1
+	case "grid":
+		if columns <= 0 {
+			columns = int(math.Ceil(math.Sqrt(float64(images))))
+		}
+	}
+	alignments := map[string]convert.Alignment{
+		"left": convert.AlignStart, "centre": convert.AlignCentre, "right": convert.AlignEnd,
+		"top": convert.AlignStart, "middle": convert.AlignCentre, "bottom": convert.AlignEnd,
+	}
+	return convert.TileOptions{
+		Columns:      columns,
+		Padding:      padding,
+		Separator:    separator,
+		RowSeparator: rowSeparator,
+		HAlign:       alignments[hAlign],
+		VAlign:       alignments[vAlign],
+	}
+}
+
+// inputFileList collects the values of -i, which can be given more than once
+type inputFileList []string
+
+func (l *inputFileList) Set(value string, opt getopt.Option) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *inputFileList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// first returns the first input file, or "" for stdin
+func (l inputFileList) first() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+// parseCoordinates parses a comma-separated list of numbers given to an option, e.g. "x,y,w,h" for --crop,
+// or returns nil if the value is empty
+func parseCoordinates(name string, value string, format string) []int {
+	if value == "" {
+		return nil
+	}
+	parts := strings.Split(value, ",")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) != len(parts) || len(parts) != strings.Count(format, ",")+1 {
+		fmt.Fprintf(os.Stderr, "invalid --%s %q, expected %s\n", name, value, format)
+		os.Exit(1)
+	}
+	return numbers
+}
+
+// transparency returns the overlay transparency given by --transparent-char & --transparent-bg
+func transparency(char string, bg int) convert.Transparency {
+	var t convert.Transparency
+	if char != "" {
+		t.Chars = []rune{[]rune(char)[0]}
+	}
+	if bg >= 0 {
+		t.BGs = []int{bg}
+	}
+	return t
+}
+
+// readFile reads & decodes an additional input file (e.g. for --overlay), without its SAUCE record
+func readFile(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		log.DebugFprintf("Error reading file %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	_, fileData, err := convert.SAUCERecord(raw, parse.DetectEncoding(raw))
+	if err != nil {
+		log.DebugFprintf("\x1b[91mUnable to determine file info: \x1b[0m%v\n", err)
+		os.Exit(1)
+	}
+	return fileData
+}
+
 func writeOutput(args Args, output string) {
 	if args.Stdout {
 		fmt.Print(output)
@@ -0,0 +1,254 @@
+package convert
+
+import "slices"
+
+// Avatar control characters (see DecodeAvatar)
+const (
+	avatarClear   = '\x0c' // ^L
+	avatarCommand = '\x16' // ^V
+	avatarRepeat  = '\x19' // ^Y
+)
+
+// avatarDefaultAttribute is the attribute that the screen is cleared to (cyan on black)
+const avatarDefaultAttribute byte = 0x03
+
+// avatarScreen is the grid of cells that Avatar commands are drawn into
+type avatarScreen struct {
+	width  int
+	cells  [][]Cell
+	x, y   int
+	attr   int // the current text mode attribute, or -1 for the default colours
+	insert bool
+}
+
+// DecodeAvatar converts Avatar/0 & Avatar/0+ text (CP437 decoded) to tokenized ANSI lines,
+// by drawing it into a grid of cells that wraps at the given width (0 disables wrapping).
+//
+// Avatar/0 commands:
+//   - ^L clears the screen, and resets the attribute to cyan on black
+//   - ^Y <char> <n> repeats a character n times
+//   - ^V^A <attr> sets the text mode attribute (see DecodeBinaryText), without blink
+//   - ^V^B turns blink on
+//   - ^V^C, ^V^D, ^V^E & ^V^F move the cursor up, down, left & right
+//   - ^V^G clears to the end of the line
+//   - ^V^H <row> <column> moves the cursor to a position (from 1)
+//
+// Avatar/0+ commands:
+//   - ^V^I turns insert mode on, until the next command
+//   - ^V^J & ^V^K <n> <top> <left> <bottom> <right> scroll an area up or down by n lines
+//   - ^V^L <attr> <rows> <columns> clears an area from the cursor, and sets the attribute
+//   - ^V^M <attr> <char> <rows> <columns> fills an area from the cursor with a character, and sets the attribute
+//   - ^V^N deletes the character at the cursor
+//   - ^V^Y <n> <n chars> <times> repeats a pattern of characters
+func DecodeAvatar(s string, width int) [][]ANSILineToken {
+	runes := []rune(s)
+	// param returns the byte value of the rune at i, as parameters are raw bytes that were decoded as CP437
+	param := func(i int) int {
+		if i >= len(runes) {
+			return 0
+		}
+		b, _ := CP437Byte(runes[i])
+		return int(b)
+	}
+
+	screen := &avatarScreen{width: width, cells: make([][]Cell, 0), attr: -1}
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\r':
+			screen.x = 0
+		case '\n':
+			screen.x, screen.y = 0, screen.y+1
+		case '\x1a':
+			// EOF marker
+			i = len(runes)
+		case avatarClear:
+			screen.cells, screen.x, screen.y = screen.cells[:0], 0, 0
+			screen.attr = int(avatarDefaultAttribute)
+		case avatarRepeat:
+			if i+2 < len(runes) {
+				for range param(i + 2) {
+					screen.put(runes[i+1])
+				}
+			}
+			i += 2
+		case avatarCommand:
+			if i+1 < len(runes) {
+				i += screen.command(param(i+1), runes[i+2:])
+			}
+			i++
+		default:
+			screen.put(r)
+		}
+	}
+
+	// rows that were never drawn to (e.g. skipped over by the cursor) are blank
+	for y := range screen.cells {
+		if screen.cells[y] == nil {
+			screen.cells[y] = []Cell{}
+		}
+	}
+	return CellsToTokens(screen.cells)
+}
+
+// command runs the ^V command with the given code, where args are the runes that follow it,
+// returning the number of runes used as arguments
+func (s *avatarScreen) command(code int, args []rune) int {
+	arg := func(i int) int {
+		if i >= len(args) {
+			return 0
+		}
+		b, _ := CP437Byte(args[i])
+		return int(b)
+	}
+	s.insert = false
+	switch code {
+	case 0x01:
+		s.attr = arg(0) & 0x7f
+		return 1
+	case 0x02:
+		if s.attr < 0 {
+			s.attr = 0x07
+		}
+		s.attr |= 0x80
+	case 0x03:
+		s.y = max(s.y-1, 0)
+	case 0x04:
+		s.y++
+	case 0x05:
+		s.x = max(s.x-1, 0)
+	case 0x06:
+		s.x++
+	case 0x07:
+		if s.y < len(s.cells) && s.x < len(s.cells[s.y]) {
+			s.cells[s.y] = s.cells[s.y][:s.x]
+		}
+		for x := s.x; x < s.width; x++ {
+			s.set(x, s.y, ' ')
+		}
+	case 0x08:
+		s.y, s.x = max(arg(0)-1, 0), max(arg(1)-1, 0)
+		return 2
+	case 0x09:
+		s.insert = true
+	case 0x0a, 0x0b:
+		s.scroll(arg(0), arg(1)-1, arg(2)-1, arg(3)-1, arg(4)-1, code == 0x0a)
+		return 5
+	case 0x0c:
+		s.attr = arg(0)
+		s.fill(' ', arg(1), arg(2))
+		return 3
+	case 0x0d:
+		s.attr = arg(0)
+		if len(args) > 1 {
+			s.fill(args[1], arg(2), arg(3))
+		}
+		return 4
+	case 0x0e:
+		if s.y < len(s.cells) && s.x < len(s.cells[s.y]) {
+			s.cells[s.y] = slices.Delete(s.cells[s.y], s.x, s.x+1)
+		}
+	case 0x19:
+		n := arg(0)
+		if n+1 < len(args) {
+			for range arg(n + 1) {
+				for _, r := range args[1 : n+1] {
+					s.put(r)
+				}
+			}
+		}
+		return n + 2
+	}
+	return 0
+}
+
+// cell returns a cell drawn with the current attribute
+func (s *avatarScreen) cell(r rune) Cell {
+	if s.attr < 0 {
+		return Cell{R: r}
+	}
+	attr := byte(s.attr)
+	cell := Cell{
+		FG: "\x1b[" + ansiCode(int(dosColour(attr&0x0f)), false) + "m",
+		BG: "\x1b[" + ansiCode(int(dosColour((attr>>4)&0x07)), true) + "m",
+		R:  r,
+	}
+	if attr&0x80 != 0 {
+		cell.FG = "\x1b[5m" + cell.FG
+	}
+	return cell
+}
+
+// set draws a rune at a position
+func (s *avatarScreen) set(x, y int, r rune) {
+	s.grow(x, y)
+	s.cells[y][x] = s.cell(r)
+}
+
+// grow adds blank cells to the grid so that it covers a position
+func (s *avatarScreen) grow(x, y int) {
+	for len(s.cells) <= y {
+		s.cells = append(s.cells, nil)
+	}
+	for len(s.cells[y]) <= x {
+		s.cells[y] = append(s.cells[y], Cell{R: ' '})
+	}
+}
+
+// put draws a rune at the cursor and moves the cursor right, wrapping at the screen width
+func (s *avatarScreen) put(r rune) {
+	if s.width > 0 && s.x >= s.width {
+		s.x, s.y = 0, s.y+1
+	}
+	if s.insert && s.y < len(s.cells) && s.x < len(s.cells[s.y]) {
+		s.cells[s.y] = slices.Insert(s.cells[s.y], s.x, Cell{R: ' '})
+		if s.width > 0 && len(s.cells[s.y]) > s.width {
+			s.cells[s.y] = s.cells[s.y][:s.width]
+		}
+	}
+	s.set(s.x, s.y, r)
+	s.x++
+}
+
+// fill draws a rune over an area of rows x columns from the cursor, leaving the cursor where it is
+func (s *avatarScreen) fill(r rune, rows, columns int) {
+	for y := s.y; y < s.y+rows; y++ {
+		for x := s.x; x < s.x+columns; x++ {
+			s.set(x, y, r)
+		}
+	}
+}
+
+// scroll moves the cells in an area (from 0, inclusive) up or down by n lines, clearing the lines left behind
+func (s *avatarScreen) scroll(n, top, left, bottom, right int, up bool) {
+	if n <= 0 || top < 0 || left < 0 || bottom < top || right < left {
+		return
+	}
+	for y := top; y <= bottom; y++ {
+		s.grow(right, y)
+	}
+	for i := range bottom - top + 1 {
+		y := top + i
+		if !up {
+			y = bottom - i
+		}
+		from := y + n
+		if !up {
+			from = y - n
+		}
+		for x := left; x <= right; x++ {
+			if from >= top && from <= bottom {
+				s.cells[y][x] = s.at(x, from)
+			} else {
+				s.set(x, y, ' ')
+			}
+		}
+	}
+}
+
+// at returns the cell at a position, or a blank cell if nothing has been drawn there
+func (s *avatarScreen) at(x, y int) Cell {
+	if y < len(s.cells) && x < len(s.cells[y]) {
+		return s.cells[y][x]
+	}
+	return Cell{R: ' '}
+}
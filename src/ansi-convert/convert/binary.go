@@ -0,0 +1,178 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// cp437Glyphs are the glyphs drawn for the CP437 control characters (0x00-0x1F),
+// which aren't part of the charmap encoding
+const cp437Glyphs = " ☺☻♥♦♣♠•◘○◙♂♀♪♫☼►◄↕‼¶§▬↨↑↓→←∟↔▲▼"
+
+// cp437Runes maps each CP437 byte to the rune that it is drawn as
+var cp437Runes = func() [256]rune {
+	var runes [256]rune
+	for i, r := range []rune(cp437Glyphs) {
+		runes[i] = r
+	}
+	for b := 0x20; b < 0x100; b++ {
+		runes[b] = charmap.CodePage437.DecodeByte(byte(b))
+	}
+	runes[0x7F] = '⌂'
+	return runes
+}()
+
+// CP437Rune returns the rune that a CP437 byte is drawn as, including the glyphs of the control characters
+func CP437Rune(b byte) rune {
+	return cp437Runes[b]
+}
+
+// CP437Byte returns the CP437 byte value used to draw a rune, and false if the rune
+// isn't in code page 437 (in which case '?' is returned)
+func CP437Byte(r rune) (byte, bool) {
+	if r == '⌂' {
+		return 0x7F, true
+	}
+	if r > 0 && r < 0x20 {
+		return byte(r), true
+	}
+	if i := strings.IndexRune(cp437Glyphs, r); i > 0 {
+		return byte(len([]rune(cp437Glyphs[:i]))), true
+	}
+	if b, ok := charmap.CodePage437.EncodeRune(r); ok {
+		return b, true
+	}
+	return '?', false
+}
+
+// dosColours maps the IBM PC colour order of text mode attributes (black, blue, green, cyan, red, magenta, brown, grey)
+// to the ANSI colour order (black, red, green, yellow, blue, magenta, cyan, white), and back again
+var dosColours = [8]byte{0, 4, 2, 6, 1, 5, 3, 7}
+
+// dosColour converts a text mode attribute colour (0-15) to an ANSI colour index, or an ANSI colour index to
+// an attribute colour, keeping the high intensity bit
+func dosColour(index byte) byte {
+	return dosColours[index&0x07] | index&0x08
+}
+
+// cp437Bytes converts a CP437 decoded string back to its bytes, for the binary formats where
+// every byte is a character or attribute (see CP437Byte)
+func cp437Bytes(s string) []byte {
+	data := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, _ := CP437Byte(r)
+		data = append(data, b)
+	}
+	return data
+}
+
+// BinaryTextWidth returns the width of a Binary Text file, which is stored in the SAUCE FileType as half the width,
+// or 160 columns if it isn't set
+func BinaryTextWidth(info SAUCE) int {
+	if info.FileType == 0 {
+		return 160
+	}
+	return int(info.FileType) * 2
+}
+
+// DecodeBinaryText converts Binary Text (.BIN) data to a DOS ANSI string (see TokeniseDOSANSIString).
+// The data is a series of character & attribute byte pairs, with width pairs on each line:
+//   - the character is a CP437 byte, where the control characters are drawn as glyphs
+//   - the attribute has the foreground colour in bits 0-3, the background colour in bits 4-6,
+//     and blink (or a high intensity background when iCE colour is on) in bit 7.
+//     The colours are in the IBM PC order, where 1 is blue (see dosColour).
+func DecodeBinaryText(data []byte, width int) string {
+	if width <= 0 {
+		width = 160
+	}
+	var builder strings.Builder
+	for i := 0; i+1 < len(data); i += 2 {
+		x := (i / 2) % width
+		attr := data[i+1]
+		if x == 0 || attr != data[i-1] {
+			builder.WriteString(attributeSGR(attr))
+		}
+		builder.WriteRune(CP437Rune(data[i]))
+		if x == width-1 || i+3 >= len(data) {
+			builder.WriteString("\x1b[0m\n")
+		}
+	}
+	return builder.String()
+}
+
+// attributeSGR returns the SGR code that sets the colours of a text mode attribute byte, as bold & blink on DOS.
+// Bold & blink come after the colours, as a ";5;" parameter would be read as a 256 colour code.
+func attributeSGR(attr byte) string {
+	params := fmt.Sprintf("0;%d;%d", 30+dosColour(attr&0x07), 40+dosColour((attr>>4)&0x07))
+	if attr&0x08 != 0 {
+		params += ";1"
+	}
+	if attr&0x80 != 0 {
+		params += ";5"
+	}
+	return "\x1b[" + params + "m"
+}
+
+// EncodeBinaryText converts tokenized ANSI lines to Binary Text (.BIN) data (see DecodeBinaryText),
+// returning the data and its width, which is padded to an even number of columns so that it can be stored in SAUCE.
+// The width is at least 2 columns, as a SAUCE FileType of 0 means 160 columns.
+// Colours that aren't one of the 16 VGA colours are matched to the nearest.
+// High intensity backgrounds need iCE colour, otherwise they are drawn as the normal intensity background,
+// and blink is kept instead.
+// Runes that aren't in code page 437 are written as '?'.
+func EncodeBinaryText(lines [][]ANSILineToken, iceColour bool) ([]byte, int, error) {
+	grid := TokensToCells(lines)
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	width = max(width+width%2, 2)
+	if width > 510 {
+		return nil, 0, fmt.Errorf("invalid width %d, binary text can be at most 510 columns wide", width)
+	}
+
+	data := make([]byte, 0, len(grid)*width*2)
+	for _, row := range grid {
+		for x := range width {
+			char, attr := byte(' '), byte(0x07)
+			if x < len(row) {
+				if row[x].R != 0 {
+					char, _ = CP437Byte(row[x].R)
+				}
+				attr = textAttribute(ParseStyle(row[x].FG+row[x].BG), iceColour)
+			}
+			data = append(data, char, attr)
+		}
+	}
+	return data, width, nil
+}
+
+// textAttribute returns the text mode attribute byte of a style (see DecodeBinaryText)
+func textAttribute(style Style, iceColour bool) byte {
+	fg, bg := vgaIndex(style.FG, 7), vgaIndex(style.BG, 0)
+	if style.Bold && fg < 8 {
+		fg += 8
+	}
+	if style.Reverse {
+		fg, bg = bg, fg
+	}
+	attr := dosColour(fg) | dosColour(bg&0x07)<<4
+	if (iceColour && bg >= 8) || (!iceColour && style.Blink) {
+		attr |= 0x80
+	}
+	return attr
+}
+
+// vgaIndex returns the index of the nearest of the 16 VGA colours to a colour
+func vgaIndex(c Colour, defaultIndex uint8) byte {
+	switch {
+	case c.Kind == ColourDefault:
+		return defaultIndex
+	case c.Kind == ColourIndexed && c.Index < 16:
+		return c.Index
+	default:
+		return byte(NearestColour(c.ToRGB(defaultIndex), VGAPalette[:16], MetricCIEDE2000))
+	}
+}
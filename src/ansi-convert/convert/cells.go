@@ -0,0 +1,118 @@
+package convert
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Cell represents a single display column of ANSI art.
+// - FG is the foreground color code
+// - BG is the background color code, and
+// - R is the rune drawn in the cell.
+//
+// Double-width runes occupy two cells, the second of which is a continuation cell with R == 0.
+type Cell struct {
+	FG string
+	BG string
+	R  rune
+}
+
+// runeWidth returns the display width of a rune, using a width of 1 for ascii
+func runeWidth(r rune) int {
+	if r < 128 {
+		return 1
+	}
+	return runewidth.RuneWidth(r)
+}
+
+// TokensToCells expands tokenised lines into a grid of cells, one per display column.
+// Double-width runes are followed by a continuation cell so that every row index is a column.
+func TokensToCells(lines [][]ANSILineToken) [][]Cell {
+	grid := make([][]Cell, len(lines))
+	for i, tokens := range lines {
+		row := make([]Cell, 0)
+		for _, token := range tokens {
+			for _, r := range token.T {
+				w := runeWidth(r)
+				if w == 0 {
+					continue
+				}
+				row = append(row, Cell{FG: token.FG, BG: token.BG, R: r})
+				if w == 2 {
+					row = append(row, Cell{FG: token.FG, BG: token.BG, R: 0})
+				}
+			}
+		}
+		grid[i] = row
+	}
+	return grid
+}
+
+// CellsToTokens collapses a grid of cells back into tokenised lines,
+// merging adjacent cells with the same colours into a single token.
+// When a cell drops back to the default colours after a coloured cell, a reset is inserted,
+// and when a bold (\x1b[1m) or blinking (\x1b[5m) cell is followed by one without, the modifiers are reset.
+func CellsToTokens(grid [][]Cell) [][]ANSILineToken {
+	lines := make([][]ANSILineToken, len(grid))
+
+	for i, row := range grid {
+		tokens := make([]ANSILineToken, 0)
+		var text strings.Builder
+		prev := Cell{}
+		curr := Cell{}
+
+		flush := func() {
+			if text.Len() == 0 {
+				return
+			}
+			fg, bg := curr.FG, curr.BG
+			if fg == "" && bg == "" && (prev.FG != "" || prev.BG != "") {
+				fg = "\x1b[0m"
+			} else if modifiers(prev.FG) != "" && modifiers(prev.FG) != modifiers(fg) {
+				fg = "\x1b[0m" + fg
+				if bg == "" {
+					bg = "\x1b[49m"
+				}
+			} else {
+				if fg == "" && prev.FG != "" {
+					fg = "\x1b[39m"
+				}
+				if bg == "" && prev.BG != "" {
+					bg = "\x1b[49m"
+				}
+			}
+			tokens = append(tokens, ANSILineToken{FG: fg, BG: bg, T: text.String()})
+			text.Reset()
+			prev = curr
+		}
+
+		for x, cell := range row {
+			r := cell.R
+			if r == 0 {
+				// skip the continuation half of a double-width rune, but fill any orphaned halves
+				if x > 0 && row[x-1].R != 0 && runeWidth(row[x-1].R) == 2 {
+					continue
+				}
+				r = ' '
+			}
+			if text.Len() > 0 && (cell.FG != curr.FG || cell.BG != curr.BG) {
+				flush()
+			}
+			curr = Cell{FG: cell.FG, BG: cell.BG}
+			text.WriteRune(r)
+		}
+		flush()
+		lines[i] = tokens
+	}
+	return lines
+}
+
+// modifiers returns the bold (\x1b[1m) and blink (\x1b[5m) codes at the start of a foreground code
+func modifiers(fg string) string {
+	end := 0
+	for strings.HasPrefix(fg[end:], "\x1b[1m") || strings.HasPrefix(fg[end:], "\x1b[5m") {
+		end += len("\x1b[1m")
+	}
+	return fg[:end]
+}
@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"slices"
+)
+
+// Transparency selects the cells of an overlay that show the image below when composing
+//   - Chars are the runes that are transparent, e.g. ' '
+//   - BGs are the background colours (256 colour palette indexes) that are transparent.
+//     Cells without a background colour count as colour 0 (black), as on DOS.
+type Transparency struct {
+	Chars []rune
+	BGs   []int
+}
+
+// transparent returns true if the image below should show through a cell of the overlay
+func (t Transparency) transparent(cell Cell) bool {
+	if slices.Contains(t.Chars, cell.R) {
+		return true
+	}
+	if len(t.BGs) == 0 {
+		return false
+	}
+	bg := ParseStyle(cell.FG + cell.BG).BG
+	switch bg.Kind {
+	case ColourDefault:
+		return slices.Contains(t.BGs, 0)
+	case ColourIndexed:
+		return slices.Contains(t.BGs, int(bg.Index))
+	default:
+		return false
+	}
+}
+
+// Compose places an overlay on top of a base image, with the top left of the overlay at column x of line y (from 0).
+// The result is large enough to hold both images, and parts of the overlay at negative offsets are cut off.
+// Cells of the overlay that match the transparency, and cells past the end of the overlay's lines,
+// show the base image instead.
+// A double-width rune that is half covered by the overlay is replaced with a space.
+func Compose(base, overlay [][]ANSILineToken, x, y int, transparency Transparency) [][]ANSILineToken {
+	baseGrid, overlayGrid := TokensToCells(base), TokensToCells(overlay)
+
+	width, height := 0, max(len(baseGrid), y+len(overlayGrid))
+	for _, row := range baseGrid {
+		width = max(width, len(row))
+	}
+	for _, row := range overlayGrid {
+		width = max(width, x+len(row))
+	}
+
+	grid := make([][]Cell, height)
+	for i := range grid {
+		grid[i] = make([]Cell, width)
+		if i < len(baseGrid) {
+			copy(grid[i], baseGrid[i])
+		}
+		for j := range grid[i] {
+			if i >= len(baseGrid) || j >= len(baseGrid[i]) {
+				grid[i][j] = Cell{R: ' '}
+			}
+		}
+	}
+
+	for i, row := range overlayGrid {
+		for j, cell := range row {
+			if y+i < 0 || x+j < 0 {
+				continue
+			}
+			// the continuation of a double-width rune goes with the rune
+			lead := cell
+			if cell.R == 0 && j > 0 {
+				lead = row[j-1]
+			}
+			if transparency.transparent(lead) {
+				continue
+			}
+			grid[y+i][x+j] = cell
+		}
+	}
+
+	for _, row := range grid {
+		for j, cell := range row {
+			if cell.R != 0 && runeWidth(cell.R) == 2 && (j+1 >= len(row) || row[j+1].R != 0) {
+				row[j].R = ' '
+			}
+		}
+	}
+	return CellsToTokens(grid)
+}
@@ -66,10 +66,8 @@ func SanitiseUnicodeString(s string, justifyLines bool) string {
 			lineBuilder.WriteString(token.BG)
 			lineBuilder.WriteString(token.T)
 			lineLen += parse.UnicodeStringLength(token.T)
-			if token.FG == "\x1b[0m" {
-				hasReset = true
-			} else if token.FG != "" || token.BG != "" {
-				hasReset = false
+			if token.FG != "" || token.BG != "" {
+				hasReset = token.Style().IsDefault()
 			}
 		}
 		// Ensure line ends with reset before padding, if not already present
@@ -109,13 +107,16 @@ func OptimiseANSITokens(lines [][]ANSILineToken) [][]ANSILineToken {
 		}
 		var optimisedTokens []ANSILineToken
 		var lastFG, lastBG string
+		var lastStyle Style
 
 		for _, tok := range tokens {
+			style := tok.Style()
 			// Ignore empty reset tokens
-			if tok.FG == "\x1b[0m" && tok.T == "" {
+			if tok.T == "" && tok.FG != "" && style.IsDefault() {
 				continue
 			}
-			if len(optimisedTokens) > 0 && tok.FG == lastFG && tok.BG == lastBG {
+			// Merge tokens with the same style, even if it is selected by different codes
+			if len(optimisedTokens) > 0 && style == lastStyle {
 				optimisedTokens[len(optimisedTokens)-1].T += tok.T
 			} else {
 				if tok.FG != lastFG && tok.BG == lastBG {
@@ -128,7 +129,7 @@ func OptimiseANSITokens(lines [][]ANSILineToken) [][]ANSILineToken {
 					// Both changed or both new
 					optimisedTokens = append(optimisedTokens, tok)
 				}
-				lastFG, lastBG = tok.FG, tok.BG
+				lastFG, lastBG, lastStyle = tok.FG, tok.BG, style
 			}
 		}
 		optimisedLines = append(optimisedLines, optimisedTokens)
@@ -144,13 +145,37 @@ func OptimiseANSITokens(lines [][]ANSILineToken) [][]ANSILineToken {
 // colors and text segments.
 // Returns a 2D slice where each inner slice represents tokens for one line.
 func TokeniseANSIString(msg string) [][]ANSILineToken {
+	return tokeniseANSIString(msg, false, false)
+}
+
+// TokeniseDOSANSIString tokenises a string like TokeniseANSIString, but gives bold & blink their DOS meaning:
+//   - bold (1) is high intensity, so the first 8 foreground colours become bright (90-97)
+//   - blink (5) is a high intensity background (100-107) when iCE colour is enabled,
+//     otherwise it is kept as a blink code (\x1b[5m) at the start of the foreground
+//
+// The intensity & blink state is tracked across codes and lines until it is reset (0, 22 or 25),
+// e.g. "\x1b[1m\x1b[31mA" and "\x1b[31;1mA" both produce a "\x1b[91m" token.
+func TokeniseDOSANSIString(msg string, iceColour bool) [][]ANSILineToken {
+	return tokeniseANSIString(msg, true, iceColour)
+}
+
+func tokeniseANSIString(msg string, dos bool, iceColour bool) [][]ANSILineToken {
 	isColour := false
 	isReset := false
 	fg := ""
 	bg := ""
 	styleModifier := ""          // Track style modifiers like \x1b[1m (bold)
 	hadStyleBeforeReset := false // Track if there was a style modifier before the last reset
+	bold, blink := false, false  // Track intensity & blink (only used for DOS tokenising)
 	lines := make([][]ANSILineToken, 0)
+
+	// newToken creates a token, applying the intensity & blink state when tokenising DOS ANSI
+	newToken := func(fg, bg, text string) ANSILineToken {
+		if dos {
+			fg, bg = intensify(fg, bg, bold, blink, iceColour)
+		}
+		return ANSILineToken{fg, bg, text}
+	}
 	lineSlice := strings.Split(msg, "\n")
 
 	for i, line := range lineSlice {
@@ -193,13 +218,16 @@ func TokeniseANSIString(msg string) [][]ANSILineToken {
 							tokens[len(tokens)-1] = ANSILineToken{prevToken.FG, "\x1b[49m", prevToken.T}
 						}
 						if isReset {
-							tokens = append(tokens, ANSILineToken{"\x1b[0m", "", text})
+							tokens = append(tokens, newToken("\x1b[0m", "", text))
 							isReset = false
 						} else {
-							tokens = append(tokens, ANSILineToken{fg, bg, text})
+							tokens = append(tokens, newToken(fg, bg, text))
 						}
 						text = ""
 					}
+					if dos {
+						bold, blink = sgrIntensity(colour, bold, blink)
+					}
 
 					// Check for 256-color or true color codes first (contains ;5; or ;2;)
 					if strings.Contains(colour, ";5;") || strings.Contains(colour, ";2;") {
@@ -269,7 +297,7 @@ func TokeniseANSIString(msg string) [][]ANSILineToken {
 						// When we see a reset, check if there's pending text first
 						if text != "" {
 							// Flush text with current color before reset
-							tokens = append(tokens, ANSILineToken{fg, bg, text})
+							tokens = append(tokens, newToken(fg, bg, text))
 							text = ""
 						}
 						// Track if we had a style modifier before this reset
@@ -342,7 +370,7 @@ func TokeniseANSIString(msg string) [][]ANSILineToken {
 		}
 		if colour != "" || text != "" {
 			if isReset {
-				tokens = append(tokens, ANSILineToken{"\x1b[0m", "", text})
+				tokens = append(tokens, newToken("\x1b[0m", "", text))
 				isReset = false
 			} else {
 				// Don't replace empty reset tokens - preserve them
@@ -354,7 +382,7 @@ func TokeniseANSIString(msg string) [][]ANSILineToken {
 					prevToken := tokens[len(tokens)-1]
 					tokens[len(tokens)-1] = ANSILineToken{prevToken.FG, "\x1b[49m", prevToken.T}
 				}
-				tokens = append(tokens, ANSILineToken{fg, bg, text})
+				tokens = append(tokens, newToken(fg, bg, text))
 			}
 		}
 		if len(tokens) > 0 {
@@ -615,7 +643,14 @@ func AdjustANSILineWidths(lines [][]ANSILineToken, targetWidth int, targetLines
 // It removes SAUCE metadata and adds reset codes before line endings for clean display.
 // Lines are padded to the character width specified in SAUCE (or 80 by default).
 // Long lines are wrapped at the character width boundary.
-// The ANSI codes are passed through unchanged (CP437 decoding is done in main.go).
+// Bold & blink are converted to high intensity colours as on DOS (see TokeniseDOSANSIString), following the
+// iCE colour flag of the SAUCE record. Other ANSI codes are passed through unchanged (CP437 decoding is done in main.go).
+// Binary Text files (DataTypeBinaryText) are converted to DOS ANSI first (see DecodeBinaryText),
+// and XBin files (DataTypeXBin) are converted directly from their image data (see XBin.Tokens).
+// The @X colour codes of PCBoard files (FileTypeCharacterPCBoard) are converted to ANSI codes (see DecodePCBoard),
+// and Avatar files (FileTypeCharacterAvatar) are drawn into a grid of tokens (see DecodeAvatar).
+// TundraDraw files (FileTypeCharacterTundraDraw) are converted directly from their data, with truecolor colours
+// (see DecodeTundra).
 func ConvertAns(s string, info SAUCE) string {
 	charWidth := 80 // Default character width for ANSI art
 	fileLines := -1
@@ -625,14 +660,49 @@ func ConvertAns(s string, info SAUCE) string {
 	if info.TInfo2.Value > 0 {
 		fileLines = int(info.TInfo2.Value) // Use number of lines from SAUCE if available
 	}
+	if info.DataType == DataTypeCharacter && info.FileType == FileTypeCharacterPCBoard {
+		s = DecodePCBoard(s)
+	}
+	if info.DataType == DataTypeCharacter && info.FileType == FileTypeCharacterAvatar {
+		// Avatar moves the cursor with its own commands, so it is drawn into a grid first
+		lines := DecodeAvatar(s, charWidth)
+		if fileLines < 0 {
+			fileLines = len(lines)
+		}
+		s = BuildANSIString(lines, 0)
+	}
+	if info.DataType == DataTypeCharacter && info.FileType == FileTypeCharacterTundraDraw {
+		lines, err := DecodeTundra(cp437Bytes(s))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing TundraDraw: %v\n", err)
+			return ""
+		}
+		return BuildANSIString(lines, 0)
+	}
+	if info.DataType == DataTypeXBin {
+		xbin, err := ParseXBin(cp437Bytes(s))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing XBin: %v\n", err)
+			return ""
+		}
+		return BuildANSIString(xbin.Tokens(), 0)
+	}
+	if info.DataType == DataTypeBinaryText {
+		// Binary Text has no escape codes or line endings, so it is first converted to DOS ANSI.
+		// Each rune of the (CP437 decoded) input is a character or attribute byte
+		data := cp437Bytes(s)
+		charWidth = BinaryTextWidth(info)
+		fileLines = (len(data)/2 + charWidth - 1) / charWidth
+		s = DecodeBinaryText(data, charWidth)
+	}
 
 	// Remove carriage returns (\r) from DOS line ending
 	s = strings.ReplaceAll(s, "\r", "")
 
 	hasTrailingNewline := strings.HasSuffix(s, "\n")
 
-	// Tokenise the input
-	lines := TokeniseANSIString(s)
+	// Tokenise the input, with bold & blink as high intensity colours (blink only when iCE colour is on)
+	lines := TokeniseDOSANSIString(s, info.HasNonBlinkMode())
 
 	// Adjust line widths (wrap/pad to match target width and lines)
 	lines, err := AdjustANSILineWidths(lines, charWidth, fileLines)
@@ -646,25 +716,29 @@ func ConvertAns(s string, info SAUCE) string {
 	builder.Grow(len(s) + len(lines)*charWidth) // Pre-allocate
 
 	for lineIdx, line := range lines {
-		prevBG := ""
+		current := Style{} // the style that the codes written so far on this line leave the terminal in
+		// the last background selected by a token on this line, until a reset
+		prevBG, hasBG := Colour{}, false
 		// Write the tokens for this line
 		for i, token := range line {
 			fg, bg := token.FG, token.BG
+			style := token.Style()
+			// reset tokens start with a foreground code that selects the default style, e.g. "\x1b[0m"
+			reset := fg != "" && ParseStyle(fg).IsDefault()
 
 			// Handle empty reset tokens in the middle of lines
-			if token.FG == "\x1b[0m" && token.BG == "" && token.T == "" {
+			if reset && bg == "" && token.T == "" {
 				// This is an empty reset token - write it and continue
 				builder.WriteString("\x1b[0m")
-				prevBG = "" // Reset clears background
+				current, hasBG = Style{}, false
 				continue
 			}
 
 			// Don't write double reset at end of line (from padding)
-			// Check this BEFORE modifying fg/bg
-			if i == len(line)-1 && token.FG == "\x1b[0m" && token.BG == "\x1b[0m" {
+			if i == len(line)-1 && reset && bg != "" && style.IsDefault() {
 				// This is padding token - write reset first if previous token had non-default BG
 				// Default BG is black (\x1b[40m) or explicitly cleared (\x1b[49m)
-				if prevBG != "" && prevBG != "\x1b[40m" && prevBG != "\x1b[49m" {
+				if hasBG && prevBG.Kind != ColourDefault && prevBG != IndexedColour(0) {
 					builder.WriteString("\x1b[0m")
 				}
 				builder.WriteString(token.T)
@@ -672,17 +746,22 @@ func ConvertAns(s string, info SAUCE) string {
 			}
 
 			// At line start, convert reset codes to explicit default colors
-			if i == 0 {
-				if fg == "\x1b[0m" {
-					fg = "\x1b[37m" // White foreground
-				}
-				// Don't force black background - let it be omitted if not needed
+			if i == 0 && reset {
+				fg = "\x1b[37m" // White foreground
 			}
 
 			// If previous token had a background but current doesn't, emit reset first
-			if prevBG != "" && bg == "" && fg != "\x1b[0m" {
+			if hasBG && bg == "" && !reset {
 				builder.WriteString("\x1b[0m")
+				current = Style{}
+			}
+			// Each token has its own blink state, but blink is only turned off by a code
+			next := current.Apply(fg + bg)
+			if next.Blink && !style.Blink {
+				builder.WriteString("\x1b[25m")
+				next.Blink = false
 			}
+			current = next
 
 			builder.WriteString(fg)
 			builder.WriteString(bg)
@@ -690,9 +769,9 @@ func ConvertAns(s string, info SAUCE) string {
 
 			// Update previous background state
 			if bg != "" {
-				prevBG = bg
-			} else if fg == "\x1b[0m" {
-				prevBG = "" // Reset clears background
+				prevBG, hasBG = ParseStyle(bg).BG, true
+			} else if reset {
+				hasBG = false // Reset clears background
 			}
 		}
 
@@ -0,0 +1,49 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Crop cuts a rectangle of w columns by h lines out of tokenized ANSI lines, starting at column x of line y (from 0).
+// The colours that are active at the left edge are re-emitted on the first token of each line,
+// so every line of the result can be displayed on its own.
+// Double-width runes that are cut in half by the edges become spaces, and lines (or parts of lines)
+// outside of the art are padded with spaces, so the result is always w by h.
+func Crop(lines [][]ANSILineToken, x, y, w, h int) ([][]ANSILineToken, error) {
+	if x < 0 || y < 0 {
+		return nil, fmt.Errorf("invalid crop position %d,%d, must not be negative", x, y)
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid crop size %dx%d, must be at least 1x1", w, h)
+	}
+
+	cropped := make([][]ANSILineToken, h)
+	for i := range cropped {
+		if y+i < len(lines) {
+			cropped[i] = cropLine(lines[y+i], x, w)
+		} else {
+			cropped[i] = []ANSILineToken{{FG: "", BG: "", T: strings.Repeat(" ", w)}}
+		}
+	}
+	return cropped, nil
+}
+
+// cropLine returns the w columns of a line starting at column x, padded with spaces if the line is too short.
+// Each cell keeps the colours of its token, so the colours at the left edge (including a reset) carry over.
+func cropLine(tokens []ANSILineToken, x, w int) []ANSILineToken {
+	row := TokensToCells([][]ANSILineToken{tokens})[0]
+	cells := make([]Cell, w)
+	for i := range cells {
+		cells[i] = Cell{R: ' '}
+		if x+i < len(row) {
+			cells[i] = row[x+i]
+		}
+	}
+	// a double-width rune that straddles the right edge keeps its left half as a space
+	// (one that straddles the left edge leaves a continuation cell, which is drawn as a space)
+	if last := cells[w-1]; last.R != 0 && runeWidth(last.R) == 2 {
+		cells[w-1].R = ' '
+	}
+	return CellsToTokens([][]Cell{cells})[0]
+}
@@ -0,0 +1,283 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ColourDepth is the number of colours that a terminal can display
+type ColourDepth int
+
+const (
+	Depth256  ColourDepth = 256
+	Depth16   ColourDepth = 16
+	Depth8    ColourDepth = 8
+	DepthMono ColourDepth = 2
+)
+
+// ColourMetric is the method used to find the nearest palette colour
+type ColourMetric int
+
+const (
+	// MetricCIEDE2000 matches colours by perceived difference (CIE ΔE 2000)
+	MetricCIEDE2000 ColourMetric = iota
+	// MetricEuclidean matches colours by distance in RGB space
+	MetricEuclidean
+)
+
+// DownsampleColours rewrites the colour codes of every token so that they can be displayed at the given colour depth.
+//   - 256: truecolor ("38;2;R;G;B") becomes the nearest colour of the xterm 6x6x6 cube and greyscale ramp ("38;5;N")
+//   - 16: truecolor and 256 colours become the nearest of the 16 ANSI colours (30-37 & 90-97)
+//   - 8: as for 16, but only the first 8 colours (30-37) are used, and bright colours become their normal counterparts
+//   - mono: all colour codes are removed, leaving any other attributes (e.g. bold) in place
+//
+// Background codes are rewritten in the same way (48;2, 48;5, 40-47 & 100-107).
+func DownsampleColours(lines [][]ANSILineToken, depth ColourDepth, metric ColourMetric) [][]ANSILineToken {
+	d := downsampler{depth: depth, metric: metric, cache: make(map[string]string)}
+
+	downsampled := make([][]ANSILineToken, len(lines))
+	for i, tokens := range lines {
+		downsampled[i] = make([]ANSILineToken, len(tokens))
+		for j, token := range tokens {
+			downsampled[i][j] = ANSILineToken{FG: d.rewrite(token.FG), BG: d.rewrite(token.BG), T: token.T}
+		}
+	}
+	return downsampled
+}
+
+// downsampler holds the target depth & metric, and a cache of codes that have already been rewritten
+type downsampler struct {
+	depth  ColourDepth
+	metric ColourMetric
+	cache  map[string]string
+}
+
+// rewrite downsamples each SGR code in a token's FG or BG string, e.g. "\x1b[1m\x1b[38;2;255;0;0m"
+func (d downsampler) rewrite(codes string) string {
+	if rewritten, ok := d.cache[codes]; ok {
+		return rewritten
+	}
+	var builder strings.Builder
+	for i, code := range strings.Split(codes, "\x1b[") {
+		if i == 0 {
+			builder.WriteString(code)
+			continue
+		}
+		params, ok := parseSGRParams(strings.TrimSuffix(code, "m"))
+		// an empty code (i.e. "\x1b[m") is a reset, and codes that can't be parsed are left unchanged
+		if !strings.HasSuffix(code, "m") || code == "m" || !ok {
+			builder.WriteString("\x1b[" + code)
+			continue
+		}
+		if rewritten := d.rewriteParams(params); len(rewritten) > 0 {
+			builder.WriteString("\x1b[" + strings.Join(rewritten, ";") + "m")
+		}
+	}
+	d.cache[codes] = builder.String()
+	return d.cache[codes]
+}
+
+// rewriteParams downsamples the colours in the parameters of a single SGR code
+func (d downsampler) rewriteParams(params []int) []string {
+	rewritten := make([]string, 0, len(params))
+	for _, attr := range sgrAttributes(params) {
+		code := strconv.Itoa(attr.param)
+		switch {
+		case !attr.isColour || attr.colour.Kind == ColourDefault:
+		case attr.param == 38 || attr.param == 48:
+			code = d.colourCode(attr.colour, attr.background)
+		default:
+			code = d.indexedCode(int(attr.colour.Index), attr.background)
+		}
+		if code != "" {
+			rewritten = append(rewritten, code)
+		}
+	}
+	return rewritten
+}
+
+// ansiIndex returns the palette index (0-15) of a 30-37, 40-47, 90-97 or 100-107 code
+func ansiIndex(code int) int {
+	switch {
+	case code >= 100:
+		return code - 100 + 8
+	case code >= 90:
+		return code - 90 + 8
+	default:
+		return code % 10
+	}
+}
+
+// ansiCode returns the 30-37/90-97 (or 40-47/100-107) code for a palette index (0-15)
+func ansiCode(index int, background bool) string {
+	base := 30
+	if index >= 8 {
+		base, index = 90, index-8
+	}
+	if background {
+		base += 10
+	}
+	return strconv.Itoa(base + index)
+}
+
+// indexedCode returns the code for one of the 16 ANSI colours at the target depth
+func (d downsampler) indexedCode(index int, background bool) string {
+	switch d.depth {
+	case DepthMono:
+		return ""
+	case Depth8:
+		return ansiCode(index%8, background)
+	default:
+		return ansiCode(index, background)
+	}
+}
+
+// colourCode returns the code for a 256 colour or truecolor value (from a 38/48 code) at the target depth
+func (d downsampler) colourCode(c Colour, background bool) string {
+	prefix := "38;"
+	if background {
+		prefix = "48;"
+	}
+	switch {
+	case d.depth == DepthMono:
+		return ""
+	case (d.depth == Depth8 || d.depth == Depth16) && c.Kind == ColourIndexed && c.Index < 16:
+		return d.indexedCode(int(c.Index), background)
+	case d.depth == Depth8:
+		return ansiCode(NearestColour(c.ToRGB(0), VGAPalette[:8], d.metric), background)
+	case d.depth == Depth16:
+		return ansiCode(NearestColour(c.ToRGB(0), VGAPalette[:], d.metric), background)
+	case c.Kind == ColourIndexed:
+		return fmt.Sprintf("%s5;%d", prefix, c.Index)
+	case d.depth == Depth256:
+		return fmt.Sprintf("%s5;%d", prefix, 16+NearestColour(c.RGB, xtermExtendedPalette[:], d.metric))
+	default:
+		return c.params(background)
+	}
+}
+
+// xtermExtendedPalette is the colour cube and greyscale ramp of the 256 colour palette (i.e. colours 16-255),
+// which unlike the first 16 colours don't depend on the terminal's theme
+var xtermExtendedPalette = func() [240]RGB {
+	var palette [240]RGB
+	for i := range palette {
+		palette[i] = XtermColour(uint8(i + 16))
+	}
+	return palette
+}()
+
+// NearestColour returns the index of the palette colour nearest to c, using the given metric
+func NearestColour(c RGB, palette []RGB, metric ColourMetric) int {
+	best, bestDistance := 0, math.Inf(1)
+	for i, p := range palette {
+		var distance float64
+		if metric == MetricEuclidean {
+			distance = euclideanDistance(c, p)
+		} else {
+			distance = CIEDE2000(c.Lab(), p.Lab())
+		}
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+// euclideanDistance returns the squared distance between two colours in RGB space
+func euclideanDistance(a, b RGB) float64 {
+	dr, dg, db := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+// Lab is a colour in the CIE L*a*b* colour space
+type Lab struct {
+	L, A, B float64
+}
+
+// Lab converts an sRGB colour to CIE L*a*b*, using the D65 white point
+func (c RGB) Lab() Lab {
+	linear := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := (0.2126729*r + 0.7151522*g + 0.0721750*b) / 1.0
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389.0 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27.0*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return Lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// CIEDE2000 returns the perceived difference (ΔE*00) between two colours
+func CIEDE2000(c1, c2 Lab) float64 {
+	const deg = math.Pi / 180
+
+	cab := (math.Hypot(c1.A, c1.B) + math.Hypot(c2.A, c2.B)) / 2
+	g := 0.5 * (1 - math.Sqrt(math.Pow(cab, 7)/(math.Pow(cab, 7)+math.Pow(25, 7))))
+	a1, a2 := (1+g)*c1.A, (1+g)*c2.A
+	chroma1, chroma2 := math.Hypot(a1, c1.B), math.Hypot(a2, c2.B)
+
+	hue := func(a, b float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / deg
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(a1, c1.B), hue(a2, c2.B)
+
+	dL := c2.L - c1.L
+	dC := chroma2 - chroma1
+	dh := 0.0
+	if chroma1*chroma2 != 0 {
+		dh = h2 - h1
+		if dh > 180 {
+			dh -= 360
+		} else if dh < -180 {
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(chroma1*chroma2) * math.Sin(dh/2*deg)
+
+	meanL := (c1.L + c2.L) / 2
+	meanC := (chroma1 + chroma2) / 2
+	meanH := h1 + h2
+	if chroma1*chroma2 != 0 {
+		if math.Abs(h1-h2) <= 180 {
+			meanH = (h1 + h2) / 2
+		} else if h1+h2 < 360 {
+			meanH = (h1 + h2 + 360) / 2
+		} else {
+			meanH = (h1 + h2 - 360) / 2
+		}
+	}
+
+	t := 1 - 0.17*math.Cos((meanH-30)*deg) + 0.24*math.Cos(2*meanH*deg) +
+		0.32*math.Cos((3*meanH+6)*deg) - 0.20*math.Cos((4*meanH-63)*deg)
+	dTheta := 30 * math.Exp(-math.Pow((meanH-275)/25, 2))
+	rc := 2 * math.Sqrt(math.Pow(meanC, 7)/(math.Pow(meanC, 7)+math.Pow(25, 7)))
+	sl := 1 + 0.015*math.Pow(meanL-50, 2)/math.Sqrt(20+math.Pow(meanL-50, 2))
+	sc := 1 + 0.045*meanC
+	sh := 1 + 0.015*meanC*t
+	rt := -math.Sin(2*dTheta*deg) * rc
+
+	return math.Sqrt(
+		math.Pow(dL/sl, 2) + math.Pow(dC/sc, 2) + math.Pow(dH/sh, 2) + rt*(dC/sc)*(dH/sh),
+	)
+}
@@ -0,0 +1,103 @@
+package convert
+
+import (
+	"strings"
+)
+
+// BuildMinimalANSIString reconstructs an ANSI-formatted string from tokenized lines, like BuildANSIString,
+// but tracks the terminal's state and only writes the shortest SGR code needed before each piece of text,
+// e.g. "\x1b[1;31;44m", skipping codes that don't change anything and resets that aren't needed.
+//
+// As with BuildANSIString, each line starts from the default style and the codes of each token are applied on
+// top of the codes before it.
+// Unless carryState is true, each line that leaves the style changed ends with a reset code.
+// When carryState is true, the style is carried over to the next line instead, and only changed as needed.
+// Note that terminals with background colour erase will fill new lines with a carried background colour when scrolling.
+// The last line always ends with a reset code if needed.
+func BuildMinimalANSIString(lines [][]ANSILineToken, padding int, carryState bool) string {
+	var builder strings.Builder
+	paddingStr := strings.Repeat(" ", padding)
+
+	current := Style{}
+	for i, tokens := range lines {
+		if padding > 0 {
+			// the padding shouldn't show any colours or lines carried over from the previous line
+			builder.WriteString(current.Diff(blankStyle(current)))
+			current = blankStyle(current)
+			builder.WriteString(paddingStr)
+		}
+		style := Style{}
+		for _, token := range tokens {
+			style = style.Apply(token.FG + token.BG)
+			if token.T == "" {
+				continue
+			}
+			builder.WriteString(current.Diff(style))
+			builder.WriteString(token.T)
+			current = style
+		}
+		if !carryState || i == len(lines)-1 {
+			builder.WriteString(current.Diff(Style{}))
+			current = Style{}
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// blankStyle returns the style with the attributes that are visible on a space removed,
+// i.e. the background colour, reverse, underline & strikethrough
+func blankStyle(s Style) Style {
+	s.BG = Colour{}
+	s.Reverse, s.Underline, s.Strike = false, false, false
+	return s
+}
+
+// Diff returns the shortest SGR code that changes the terminal from this style to the next,
+// either by changing only the attributes & colours that differ, or by resetting and selecting the next style.
+// An empty string is returned if the styles are the same.
+func (s Style) Diff(next Style) string {
+	if s == next {
+		return ""
+	}
+	params := make([]string, 0)
+
+	// 22 turns off both bold & dim, so re-enable either one that should stay on
+	if (s.Bold && !next.Bold) || (s.Dim && !next.Dim) {
+		params = append(params, "22")
+		s.Bold, s.Dim = false, false
+	}
+	for _, attr := range []struct {
+		from, to bool
+		on, off  string
+	}{
+		{s.Bold, next.Bold, "1", "22"}, {s.Dim, next.Dim, "2", "22"},
+		{s.Italic, next.Italic, "3", "23"}, {s.Underline, next.Underline, "4", "24"},
+		{s.Blink, next.Blink, "5", "25"}, {s.Reverse, next.Reverse, "7", "27"},
+		{s.Conceal, next.Conceal, "8", "28"}, {s.Strike, next.Strike, "9", "29"},
+	} {
+		if attr.from != attr.to {
+			if attr.to {
+				params = append(params, attr.on)
+			} else {
+				params = append(params, attr.off)
+			}
+		}
+	}
+	if s.FG != next.FG {
+		params = append(params, next.FG.params(false))
+	}
+	if s.BG != next.BG {
+		params = append(params, next.BG.params(true))
+	}
+
+	changes := strings.Join(params, ";")
+	reset := "0"
+	if next.Params() != "" {
+		reset += ";" + next.Params()
+	}
+	if len(reset) <= len(changes) {
+		return "\x1b[" + reset + "m"
+	}
+	return "\x1b[" + changes + "m"
+}
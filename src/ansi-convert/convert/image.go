@@ -0,0 +1,202 @@
+package convert
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+)
+
+// DepthTrueColor is the depth of 24-bit truecolor terminals, which can display any colour
+const DepthTrueColor ColourDepth = 1 << 24
+
+// ImageOptions controls how ImageToTokens converts an image
+//   - Width is the number of columns, where 0 uses one column per pixel (images are never enlarged).
+//     The image is scaled to keep its aspect ratio, with each cell drawing 2 (square) pixels.
+//   - Depth is the colour depth, where 0 is truecolor, and DepthMono draws the image in the default foreground colour
+//   - Metric is the nearest colour matching used for depths other than truecolor
+//   - Dither spreads the error of each colour match to the pixels around it (Floyd-Steinberg)
+type ImageOptions struct {
+	Width  int
+	Depth  ColourDepth
+	Metric ColourMetric
+	Dither bool
+}
+
+// DecodeImage decodes PNG, GIF or JPEG image data (only the first frame of an animated GIF is used)
+func DecodeImage(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// imagePixel is a pixel of an image after resizing & colour matching
+type imagePixel struct {
+	transparent bool
+	rgb         RGB
+	index       int // the palette index, for depths other than truecolor
+}
+
+// ImageToTokens converts an image to tokenized ANSI lines made of half blocks (▀ & ▄),
+// where each cell draws 2 pixels, one above the other (see ImageOptions).
+// Transparent pixels (less than 50% opacity) are drawn with the default background colour.
+func ImageToTokens(img image.Image, opts ImageOptions) [][]ANSILineToken {
+	pixels, transparent := resizeImage(img, opts.Width)
+	palette, codes := imagePalette(opts.Depth)
+
+	matched := make([][]imagePixel, len(pixels))
+	cache := make(map[RGB]int)
+	for y, row := range pixels {
+		matched[y] = make([]imagePixel, len(row))
+		for x, value := range row {
+			if transparent[y][x] {
+				matched[y][x] = imagePixel{transparent: true}
+				continue
+			}
+			c := RGB{clampChannel(value[0]), clampChannel(value[1]), clampChannel(value[2])}
+			if palette == nil {
+				matched[y][x] = imagePixel{rgb: c}
+				continue
+			}
+			index, ok := cache[c]
+			if !ok {
+				index = NearestColour(c, palette, opts.Metric)
+				cache[c] = index
+			}
+			matched[y][x] = imagePixel{rgb: palette[index], index: index}
+			if opts.Dither {
+				diffuseError(pixels, transparent, x, y, [3]float64{
+					value[0] - float64(palette[index].R),
+					value[1] - float64(palette[index].G),
+					value[2] - float64(palette[index].B),
+				})
+			}
+		}
+	}
+
+	grid := make([][]Cell, 0, (len(matched)+1)/2)
+	for y := 0; y < len(matched); y += 2 {
+		row := make([]Cell, len(matched[y]))
+		for x := range row {
+			top, bottom := matched[y][x], imagePixel{transparent: true}
+			if y+1 < len(matched) {
+				bottom = matched[y+1][x]
+			}
+			if opts.Depth == DepthMono {
+				// the second colour of the mono palette (white) is drawn, and the first (black) is left blank
+				top.transparent = top.transparent || top.index == 0
+				bottom.transparent = bottom.transparent || bottom.index == 0
+			}
+			row[x] = halfBlockCell(top, bottom, codes)
+		}
+		grid = append(grid, row)
+	}
+	return CellsToTokens(grid)
+}
+
+// halfBlockCell returns the cell that draws 2 pixels, one above the other
+func halfBlockCell(top, bottom imagePixel, codes func(p imagePixel, background bool) string) Cell {
+	switch {
+	case top.transparent && bottom.transparent:
+		return Cell{R: ' '}
+	case bottom.transparent:
+		return Cell{FG: codes(top, false), R: '▀'}
+	case top.transparent:
+		return Cell{FG: codes(bottom, false), R: '▄'}
+	case top == bottom && codes(top, true) == "":
+		return Cell{FG: codes(top, false), R: '█'}
+	case top == bottom:
+		return Cell{BG: codes(top, true), R: ' '}
+	default:
+		return Cell{FG: codes(top, false), BG: codes(bottom, true), R: '▀'}
+	}
+}
+
+// imagePalette returns the palette for a colour depth (nil for truecolor),
+// and a function that returns the colour code of a pixel
+func imagePalette(depth ColourDepth) ([]RGB, func(p imagePixel, background bool) string) {
+	switch depth {
+	case Depth256:
+		return xtermExtendedPalette[:], func(p imagePixel, background bool) string {
+			return "\x1b[" + IndexedColour(uint8(p.index+16)).params(background) + "m"
+		}
+	case Depth16, Depth8:
+		return VGAPalette[:depth], func(p imagePixel, background bool) string {
+			return "\x1b[" + ansiCode(p.index, background) + "m"
+		}
+	case DepthMono:
+		return []RGB{VGAPalette[0], VGAPalette[15]}, func(p imagePixel, background bool) string {
+			return ""
+		}
+	default:
+		return nil, func(p imagePixel, background bool) string {
+			return "\x1b[" + trueColourCode(p.rgb, background) + "m"
+		}
+	}
+}
+
+// resizeImage scales an image to the given number of columns (or its own width if 0),
+// returning the average colour of the pixels covered by each new pixel,
+// and whether each new pixel is transparent (less than 50% opacity)
+func resizeImage(img image.Image, width int) ([][][3]float64, [][]bool) {
+	bounds := img.Bounds()
+	if width <= 0 || width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	scale := float64(bounds.Dx()) / float64(max(width, 1))
+	// very wide images still need a row, unless they're empty
+	height := max(int(math.Round(float64(bounds.Dy())/scale)), min(bounds.Dy(), 1))
+
+	pixels := make([][][3]float64, height)
+	transparent := make([][]bool, height)
+	for y := range pixels {
+		pixels[y] = make([][3]float64, width)
+		transparent[y] = make([]bool, width)
+		y0 := bounds.Min.Y + int(float64(y)*scale)
+		y1 := max(bounds.Min.Y+int(float64(y+1)*scale), y0+1)
+		for x := range pixels[y] {
+			x0 := bounds.Min.X + int(float64(x)*scale)
+			x1 := max(bounds.Min.X+int(float64(x+1)*scale), x0+1)
+
+			var r, g, b, a, n float64
+			for sy := y0; sy < min(y1, bounds.Max.Y); sy++ {
+				for sx := x0; sx < min(x1, bounds.Max.X); sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+float64(pr), g+float64(pg), b+float64(pb), a+float64(pa), n+1
+				}
+			}
+			if n == 0 || a/n < 0xffff/2 {
+				transparent[y][x] = true
+				continue
+			}
+			// un-premultiply the alpha, and scale from 16 to 8 bits per channel
+			pixels[y][x] = [3]float64{r / a * 0xff, g / a * 0xff, b / a * 0xff}
+		}
+	}
+	return pixels, transparent
+}
+
+// diffuseError spreads the colour matching error of a pixel to its neighbours that haven't been matched yet,
+// using the Floyd-Steinberg weights
+func diffuseError(pixels [][][3]float64, transparent [][]bool, x, y int, err [3]float64) {
+	for _, n := range []struct {
+		dx, dy int
+		weight float64
+	}{
+		{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16},
+	} {
+		nx, ny := x+n.dx, y+n.dy
+		if ny >= len(pixels) || nx < 0 || nx >= len(pixels[ny]) || transparent[ny][nx] {
+			continue
+		}
+		for i := range err {
+			pixels[ny][nx][i] += err[i] * n.weight
+		}
+	}
+}
+
+// clampChannel rounds a colour channel to the nearest value from 0 to 255
+func clampChannel(v float64) uint8 {
+	return uint8(math.Round(max(0, min(255, v))))
+}
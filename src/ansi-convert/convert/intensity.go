@@ -0,0 +1,66 @@
+package convert
+
+import "strings"
+
+// sgrIntensity returns the bold & blink state after applying an SGR code, e.g. "\x1b[0;1;31m"
+func sgrIntensity(code string, bold, blink bool) (bool, bool) {
+	s := Style{Bold: bold, Blink: blink}.Apply(code)
+	return s.Bold, s.Blink
+}
+
+// sgrColourIndex returns the palette index (0-15) of a 16 colour foreground (or background) code,
+// e.g. 1 for "\x1b[31m" or "\x1b[38;5;1m", 9 for "\x1b[91m", or -1 if the code doesn't select one of the 16 colours.
+// The last colour in the code is used, and strings of more than one code (e.g. "\x1b[1m\x1b[31m") return -1,
+// so that their other attributes aren't lost when the colour is rewritten.
+func sgrColourIndex(code string, background bool) int {
+	if strings.Count(code, "\x1b[") != 1 {
+		return -1
+	}
+	c := ParseStyle(code).FG
+	if background {
+		c = ParseStyle(code).BG
+	}
+	if c.Kind != ColourIndexed || c.Index >= 16 {
+		return -1
+	}
+	return int(c.Index)
+}
+
+// intensify applies the DOS meaning of bold & blink to a token's colour codes.
+// Bold makes the first 8 foreground colours bright (the default foreground is light grey, so becomes white).
+// With iCE colour, blink makes the first 8 background colours bright (the default background is black, so becomes dark grey),
+// otherwise a blink code is added to the foreground.
+// Colours that aren't one of the 16 colours (e.g. truecolor) are left unchanged.
+func intensify(fg, bg string, bold, blink, iceColour bool) (string, string) {
+	reset := ""
+	if fg == "\x1b[0m" {
+		reset, fg = fg, ""
+	}
+
+	fgIndex := sgrColourIndex(fg, false)
+	if fg == "" && bold {
+		fgIndex = 7
+	}
+	if fgIndex >= 0 {
+		if bold && fgIndex < 8 {
+			fgIndex += 8
+		}
+		fg = "\x1b[" + ansiCode(fgIndex, false) + "m"
+	}
+
+	bgIndex := sgrColourIndex(bg, true)
+	if (bg == "" || bg == "\x1b[49m") && blink && iceColour {
+		bgIndex = 0
+	}
+	if bgIndex >= 0 {
+		if blink && iceColour && bgIndex < 8 {
+			bgIndex += 8
+		}
+		bg = "\x1b[" + ansiCode(bgIndex, true) + "m"
+	}
+
+	if blink && !iceColour {
+		fg = "\x1b[5m" + fg
+	}
+	return reset + fg, bg
+}
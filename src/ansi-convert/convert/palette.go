@@ -0,0 +1,133 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RGB is a 24-bit colour
+type RGB struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// Hex returns the colour in "rrggbb" form, e.g. "aa5500"
+func (c RGB) Hex() string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Palette is a set of 16 colours in ANSI colour order
+// (i.e. the colour for "\x1b[3Nm" is palette[N], and for "\x1b[9Nm" is palette[N+8])
+type Palette [16]RGB
+
+// VGAPalette is the 16 colour palette of the IBM VGA text mode
+var VGAPalette = Palette{
+	{0x00, 0x00, 0x00}, // black
+	{0xaa, 0x00, 0x00}, // red
+	{0x00, 0xaa, 0x00}, // green
+	{0xaa, 0x55, 0x00}, // brown
+	{0x00, 0x00, 0xaa}, // blue
+	{0xaa, 0x00, 0xaa}, // magenta
+	{0x00, 0xaa, 0xaa}, // cyan
+	{0xaa, 0xaa, 0xaa}, // light grey
+	{0x55, 0x55, 0x55}, // dark grey
+	{0xff, 0x55, 0x55}, // bright red
+	{0x55, 0xff, 0x55}, // bright green
+	{0xff, 0xff, 0x55}, // yellow
+	{0x55, 0x55, 0xff}, // bright blue
+	{0xff, 0x55, 0xff}, // bright magenta
+	{0x55, 0xff, 0xff}, // bright cyan
+	{0xff, 0xff, 0xff}, // white
+}
+
+// WindowsXPPalette is the default palette of the Windows XP console
+var WindowsXPPalette = Palette{
+	{0x00, 0x00, 0x00}, {0x80, 0x00, 0x00}, {0x00, 0x80, 0x00}, {0x80, 0x80, 0x00},
+	{0x00, 0x00, 0x80}, {0x80, 0x00, 0x80}, {0x00, 0x80, 0x80}, {0xc0, 0xc0, 0xc0},
+	{0x80, 0x80, 0x80}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x00, 0x00, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+// XtermPalette is the default palette of xterm
+var XtermPalette = Palette{
+	{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+	{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+	{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+// SolarizedPalette is the (dark) Solarized terminal palette
+var SolarizedPalette = Palette{
+	{0x07, 0x36, 0x42}, {0xdc, 0x32, 0x2f}, {0x85, 0x99, 0x00}, {0xb5, 0x89, 0x00},
+	{0x26, 0x8b, 0xd2}, {0xd3, 0x36, 0x82}, {0x2a, 0xa1, 0x98}, {0xee, 0xe8, 0xd5},
+	{0x00, 0x2b, 0x36}, {0xcb, 0x4b, 0x16}, {0x58, 0x6e, 0x75}, {0x65, 0x7b, 0x83},
+	{0x83, 0x94, 0x96}, {0x6c, 0x71, 0xc4}, {0x93, 0xa1, 0xa1}, {0xfd, 0xf6, 0xe3},
+}
+
+// Palettes are the built-in palettes, by name
+var Palettes = map[string]Palette{
+	"vga":       VGAPalette,
+	"xp":        WindowsXPPalette,
+	"xterm":     XtermPalette,
+	"solarized": SolarizedPalette,
+}
+
+// ParsePaletteJSON reads a custom palette from JSON, in the form
+//
+//	{"colours": ["#000000", "#aa0000", ...]}
+//
+// with exactly 16 colours in ANSI colour order. The leading '#' is optional.
+func ParsePaletteJSON(data []byte) (Palette, error) {
+	var palette Palette
+	var parsed struct {
+		Colours []string `json:"colours"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return palette, err
+	}
+	if len(parsed.Colours) != len(palette) {
+		return palette, fmt.Errorf("palette must have %d colours, found %d", len(palette), len(parsed.Colours))
+	}
+	for i, hex := range parsed.Colours {
+		c, err := ParseHexColour(hex)
+		if err != nil {
+			return palette, err
+		}
+		palette[i] = c
+	}
+	return palette, nil
+}
+
+// ParseHexColour parses a colour in "rrggbb" or "#rrggbb" form
+func ParseHexColour(hex string) (RGB, error) {
+	value := strings.TrimPrefix(hex, "#")
+	if len(value) != 6 {
+		return RGB{}, fmt.Errorf("invalid colour %q, expected #rrggbb format", hex)
+	}
+	n, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid colour %q, expected #rrggbb format", hex)
+	}
+	return RGB{uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
+
+// xtermCubeLevels are the channel values of the 6x6x6 colour cube in the xterm 256 colour palette
+var xtermCubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// XtermColour returns the RGB value of a colour in the 256 colour palette ("\x1b[38;5;Nm").
+// The first 16 colours use the VGA palette, followed by the 6x6x6 colour cube and the greyscale ramp.
+func XtermColour(n uint8) RGB {
+	switch {
+	case n < 16:
+		return VGAPalette[n]
+	case n < 232:
+		n -= 16
+		return RGB{xtermCubeLevels[n/36], xtermCubeLevels[(n/6)%6], xtermCubeLevels[n%6]}
+	default:
+		grey := 8 + 10*(n-232)
+		return RGB{grey, grey, grey}
+	}
+}
@@ -0,0 +1,124 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pcboardCodes matches the PCBoard @X colour codes (e.g. @X1F) and @...@ macros (e.g. @CLS@, @POS:40@)
+var pcboardCodes = regexp.MustCompile(`(?i)@X[0-9A-F]{2}|@[A-Z]+(:[0-9]+)?@`)
+
+// pcboardColourCode matches a single PCBoard @X colour code
+var pcboardColourCode = regexp.MustCompile(`(?i)@X[0-9A-F]{2}`)
+
+// pcboardIgnoredMacros are the PCBoard macros that don't draw anything, and are removed
+var pcboardIgnoredMacros = map[string]bool{
+	"BEEP": true, "MORE": true, "PAUSE": true, "QOFF": true, "QON": true, "WAIT": true,
+}
+
+// HasPCBoardCodes returns true if the string contains any PCBoard @X colour codes
+func HasPCBoardCodes(s string) bool {
+	return pcboardColourCode.MatchString(s)
+}
+
+// DecodePCBoard converts PCBoard text to a DOS ANSI string (see TokeniseDOSANSIString).
+//   - @Xbf sets the colours to the text mode attribute 0xbf (see DecodeBinaryText), where b is the background
+//     and f is the foreground, e.g. @X1F is bright white on blue. @X00 saves the current colours,
+//     and @XFF restores them.
+//   - @CLS@ clears the screen, so everything before it is removed
+//   - @POS:n@ moves the cursor forward to column n, drawing spaces in the current colours
+//   - @BEEP@, @MORE@, @PAUSE@, @QOFF@, @QON@ & @WAIT@ don't draw anything, and are removed
+//
+// Other macros are replaced by the BBS when the file is displayed (e.g. @USER@), so they are kept as text.
+func DecodePCBoard(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+
+	var builder strings.Builder
+	attr, saved := byte(0x07), byte(0x07)
+	column := 0
+	text := func(t string) {
+		for line, part := range strings.Split(t, "\n") {
+			if line > 0 {
+				builder.WriteString("\n")
+				column = 0
+			}
+			builder.WriteString(part)
+			column += len([]rune(part))
+		}
+	}
+
+	last := 0
+	for _, match := range pcboardCodes.FindAllStringIndex(s, -1) {
+		text(s[last:match[0]])
+		last = match[1]
+
+		code := strings.ToUpper(s[match[0]:match[1]])
+		switch {
+		case code == "@X00":
+			saved = attr
+		case code == "@XFF":
+			attr = saved
+			builder.WriteString(attributeSGR(attr))
+		case strings.HasPrefix(code, "@X"):
+			value, _ := strconv.ParseUint(code[2:], 16, 8)
+			attr = byte(value)
+			builder.WriteString(attributeSGR(attr))
+		case code == "@CLS@":
+			builder.Reset()
+			builder.WriteString(attributeSGR(attr))
+			column = 0
+		case strings.HasPrefix(code, "@POS:"):
+			n, _ := strconv.Atoi(strings.TrimSuffix(code[5:], "@"))
+			if n-1 > column {
+				text(strings.Repeat(" ", n-1-column))
+			}
+		case pcboardIgnoredMacros[strings.Trim(code, "@")]:
+		default:
+			text(s[match[0]:match[1]])
+		}
+	}
+	text(s[last:])
+	return builder.String()
+}
+
+// EncodePCBoard converts tokenized ANSI lines to CP437 encoded PCBoard text (see DecodePCBoard), with an @X code
+// wherever the colours change, and DOS (CRLF) line endings.
+// Colours are converted as for Binary Text (see EncodeBinaryText), where bit 7 of the attribute is blink,
+// or a high intensity background with iCE colour. @X00 & @XFF are never written, as they save & restore the colours,
+// so black on black is written as spaces, and 0xFF loses its high intensity background.
+// Trailing spaces with a black background are removed.
+func EncodePCBoard(lines [][]ANSILineToken, iceColour bool) []byte {
+	var builder strings.Builder
+	current := -1
+	for _, row := range TokensToCells(lines) {
+		runes, attrs := make([]rune, len(row)), make([]byte, len(row))
+		end := 0
+		for x, cell := range row {
+			runes[x], attrs[x] = cell.R, textAttribute(ParseStyle(cell.FG+cell.BG), iceColour)
+			if runes[x] == 0 {
+				// double-width runes aren't in CP437, so they are written as '?' followed by a space
+				runes[x] = ' '
+			}
+			switch attrs[x] {
+			case 0x00:
+				runes[x], attrs[x] = ' ', 0x07
+			case 0xff:
+				attrs[x] = 0x7f
+			}
+			if runes[x] != ' ' || attrs[x]&0xf0 != 0 {
+				end = x + 1
+			}
+		}
+		for x := range end {
+			if int(attrs[x]) != current {
+				current = int(attrs[x])
+				fmt.Fprintf(&builder, "@X%02X", current)
+			}
+			builder.WriteRune(runes[x])
+		}
+		builder.WriteString("\r\n")
+	}
+	return cp437Bytes(builder.String())
+}
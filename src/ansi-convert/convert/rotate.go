@@ -0,0 +1,132 @@
+package convert
+
+import (
+	"fmt"
+)
+
+// RotationMap maps each rune to the rune that looks like it after a clockwise rotation by 90°.
+// Rotations by 180° & 270° apply the map 2 & 3 times.
+var RotationMap = map[rune]rune{
+	'-': '|', '|': '-', // ascii
+	'/': '\\', '\\': '/',
+
+	'▀': '▐', '▐': '▄', '▄': '▌', '▌': '▀', // half blocks
+	'▘': '▝', '▝': '▗', '▗': '▖', '▖': '▘', // quadrants
+	'▚': '▞', '▞': '▚',
+	'▙': '▛', '▛': '▜', '▜': '▟', '▟': '▙',
+	'▁': '▏', '▏': '▔', '▔': '▕', '▕': '▁', // eighth blocks
+
+	'─': '│', '│': '─', // box chars (lines)
+	'━': '┃', '┃': '━',
+	'═': '║', '║': '═',
+	'┄': '┆', '┆': '┄',
+	'┅': '┇', '┇': '┅',
+	'┈': '┊', '┊': '┈',
+	'┉': '┋', '┋': '┉',
+	'╌': '╎', '╎': '╌',
+	'╍': '╏', '╏': '╍',
+	'╴': '╵', '╵': '╶', '╶': '╷', '╷': '╴',
+	'╸': '╹', '╹': '╺', '╺': '╻', '╻': '╸',
+
+	'┌': '┐', '┐': '┘', '┘': '└', '└': '┌', // box chars (corners)
+	'┏': '┓', '┓': '┛', '┛': '┗', '┗': '┏',
+	'╔': '╗', '╗': '╝', '╝': '╚', '╚': '╔',
+	'╭': '╮', '╮': '╯', '╯': '╰', '╰': '╭',
+	'╒': '╖', '╖': '╛', '╛': '╙', '╙': '╒',
+	'╓': '╕', '╕': '╜', '╜': '╘', '╘': '╓',
+
+	'├': '┬', '┬': '┤', '┤': '┴', '┴': '├', // box chars (tee edges)
+	'┣': '┳', '┳': '┫', '┫': '┻', '┻': '┣',
+	'╠': '╦', '╦': '╣', '╣': '╩', '╩': '╠',
+	'╞': '╥', '╥': '╡', '╡': '╨', '╨': '╞',
+	'╟': '╤', '╤': '╢', '╢': '╧', '╧': '╟',
+	'╪': '╫', '╫': '╪', // box chars (crosses)
+
+	'→': '↓', '↓': '←', '←': '↑', '↑': '→', // arrows
+	'↔': '↕', '↕': '↔',
+	'▲': '▶', '▶': '▼', '▼': '◀', '◀': '▲', // triangles
+	'△': '▷', '▷': '▽', '▽': '◁', '◁': '△',
+}
+
+// Rotate rotates tokenized ANSI lines clockwise by 90, 180 or 270 degrees, preserving formatting.
+// The lines are treated as a grid of cells (see TokensToCells), with short lines padded to the widest line,
+// and any runes found in RotationMap are substituted with their rotated counterparts.
+//
+// Double-width runes keep their place when rotated by 180 degrees. A double-width rune can't be stood on its side,
+// so when rotated by 90 or 270 degrees, both of its cells become spaces with the rune's colours.
+func Rotate(lines [][]ANSILineToken, degrees int) ([][]ANSILineToken, error) {
+	turns := 0
+	switch degrees {
+	case 90:
+		turns = 1
+	case 180:
+		turns = 2
+	case 270:
+		turns = 3
+	default:
+		return nil, fmt.Errorf("invalid rotation %d, expected 90, 180 or 270", degrees)
+	}
+
+	grid := TokensToCells(lines)
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	for i, row := range grid {
+		for len(row) < width {
+			row = append(row, Cell{R: ' '})
+		}
+		grid[i] = row
+	}
+
+	if turns == 2 {
+		return CellsToTokens(rotate180(grid)), nil
+	}
+	rotated := make([][]Cell, width)
+	for x := range rotated {
+		rotated[x] = make([]Cell, len(grid))
+	}
+	for y, row := range grid {
+		for x, cell := range row {
+			if cell.R == 0 || runeWidth(cell.R) == 2 {
+				cell.R = ' '
+			}
+			cell.R = rotateRune(cell.R, turns)
+			if turns == 1 {
+				rotated[x][len(grid)-1-y] = cell
+			} else {
+				rotated[width-1-x][y] = cell
+			}
+		}
+	}
+	return CellsToTokens(rotated), nil
+}
+
+// rotate180 reverses the rows and the cells in each row of a grid, keeping each double-width rune
+// in front of its continuation cell
+func rotate180(grid [][]Cell) [][]Cell {
+	rotated := make([][]Cell, len(grid))
+	for y, row := range grid {
+		reversed := make([]Cell, len(row))
+		for x, cell := range row {
+			cell.R = rotateRune(cell.R, 2)
+			reversed[len(row)-1-x] = cell
+		}
+		for x := 0; x+1 < len(reversed); x++ {
+			if reversed[x].R == 0 && reversed[x+1].R != 0 && runeWidth(reversed[x+1].R) == 2 {
+				reversed[x], reversed[x+1] = reversed[x+1], reversed[x]
+				x++
+			}
+		}
+		rotated[len(grid)-1-y] = reversed
+	}
+	return rotated
+}
+
+// rotateRune looks up a rune in RotationMap once for each clockwise quarter turn
+func rotateRune(r rune, turns int) rune {
+	for range turns {
+		r = getOrDefault(RotationMap, r, r)
+	}
+	return r
+}
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/log"
 	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/parse"
+	"golang.org/x/text/encoding/charmap"
 )
 
 // SAUCE data types
@@ -225,6 +228,8 @@ type SAUCE struct {
 	Comments byte       // 1 byte: Number of comment lines
 	TFlags   byte       // 1 byte: Type dependent flags
 	TInfoS   string     // 22 bytes: Type dependent string (null-terminated)
+
+	CommentLines []string // 64 bytes each: Lines from the COMNT block that precedes the record
 }
 
 func CreateSAUCERecord(data []byte, encoding string) (*SAUCE, string, error) {
@@ -269,20 +274,40 @@ func CreateSAUCERecord(data []byte, encoding string) (*SAUCE, string, error) {
 func ParseSAUCE(data []byte, encoding string) (*SAUCE, string, error) {
 	log.DebugFprintln("\x1b[1;93m> Parsing SAUCE metadata\x1b[0m")
 
+	sauce, eofIdx, err := parseSAUCERecord(data)
+	if err != nil {
+		return nil, "", err
+	}
+	strData, err := parse.DecodeFileContents(data[:eofIdx], encoding)
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding file data: %v", err)
+	}
+
+	// Return the data without the EOF marker and SAUCE record
+	return sauce, strData, nil
+}
+
+// StripSAUCE returns the file data with any SAUCE record (and its EOF marker) removed.
+// The data is returned unchanged if no valid SAUCE record is found.
+func StripSAUCE(data []byte) []byte {
+	_, eofIdx, err := parseSAUCERecord(data)
+	if err != nil {
+		return data
+	}
+	return data[:eofIdx]
+}
+
+// parseSAUCERecord reads the SAUCE record (and COMNT block, if present) from the end of the data,
+// returning the record and the index of the EOF marker that precedes them
+func parseSAUCERecord(data []byte) (*SAUCE, int, error) {
 	// SAUCE record is 128 bytes, preceded by EOF marker '\x1a'
 	// Minimum length is 129 bytes (1 byte EOF + 128 bytes SAUCE)
 	if len(data) < 129 {
-		return nil, "", fmt.Errorf("data too short to contain SAUCE record")
+		return nil, 0, fmt.Errorf("data too short to contain SAUCE record")
 	}
 
-	// Find the "\x1a" EOF marker - it should be 128 bytes from the end
-	eofIdx := len(data) - 129
-	if eofIdx < 0 || data[eofIdx] != '\x1a' {
-		return nil, "", fmt.Errorf("no valid SAUCE record found")
-	}
-
-	// SAUCE record starts right after the EOF marker
-	sauceIdx := eofIdx + 1
+	// SAUCE record is always the last 128 bytes
+	sauceIdx := len(data) - 128
 	sauceData := []byte(data[sauceIdx:])
 
 	reader := bytes.NewReader(sauceData)
@@ -296,7 +321,7 @@ func ParseSAUCE(data []byte, encoding string) (*SAUCE, string, error) {
 	// Check if this is a valid SAUCE record
 	if sauce.ID != "SAUCE" {
 		log.DebugFprintf("Invalid SAUCE record ID: '%s'", sauce.ID)
-		return nil, "", fmt.Errorf("no valid SAUCE record found")
+		return nil, 0, fmt.Errorf("no valid SAUCE record found")
 	}
 
 	// Read Version (2 bytes)
@@ -304,20 +329,20 @@ func ParseSAUCE(data []byte, encoding string) (*SAUCE, string, error) {
 	reader.Read(versionBytes)
 	sauce.Version = string(versionBytes)
 
-	// Read Title (35 bytes) - trim spaces
+	// Read Title (35 bytes) - trim spaces & decode from CP437
 	titleBytes := make([]byte, 35)
 	reader.Read(titleBytes)
-	sauce.Title = strings.TrimRight(string(titleBytes), " \x00")
+	sauce.Title = sauceString(titleBytes)
 
-	// Read Author (20 bytes) - trim spaces
+	// Read Author (20 bytes) - trim spaces & decode from CP437
 	authorBytes := make([]byte, 20)
 	reader.Read(authorBytes)
-	sauce.Author = strings.TrimRight(string(authorBytes), " \x00")
+	sauce.Author = sauceString(authorBytes)
 
-	// Read Group (20 bytes) - trim spaces
+	// Read Group (20 bytes) - trim spaces & decode from CP437
 	groupBytes := make([]byte, 20)
 	reader.Read(groupBytes)
-	sauce.Group = strings.TrimRight(string(groupBytes), " \x00")
+	sauce.Group = sauceString(groupBytes)
 
 	// Read Date (8 bytes) - trim spaces
 	dateBytes := make([]byte, 8)
@@ -338,9 +363,25 @@ func ParseSAUCE(data []byte, encoding string) (*SAUCE, string, error) {
 	// Find the null terminator
 	nullIndex := bytes.IndexByte(tinfoSBytes, 0)
 	if nullIndex != -1 {
-		sauce.TInfoS = string(tinfoSBytes[:nullIndex])
+		sauce.TInfoS = sauceString(tinfoSBytes[:nullIndex])
 	} else {
-		sauce.TInfoS = string(tinfoSBytes)
+		sauce.TInfoS = sauceString(tinfoSBytes)
+	}
+
+	// The COMNT block (if any) sits between the EOF marker and the SAUCE record
+	eofIdx := sauceIdx - 1
+	if sauce.Comments > 0 {
+		if lines, commentIdx, ok := parseSAUCEComments(data[:sauceIdx], int(sauce.Comments)); ok {
+			sauce.CommentLines = lines
+			eofIdx = commentIdx - 1
+		} else {
+			log.DebugFprintf("Invalid SAUCE comment block, expected %d lines\n", sauce.Comments)
+		}
+	}
+
+	// Find the "\x1a" EOF marker - it should be right before the comments/record
+	if eofIdx < 0 || data[eofIdx] != '\x1a' {
+		return nil, 0, fmt.Errorf("no valid SAUCE record found")
 	}
 
 	// Populate TInfo field names based on DataType and FileType
@@ -370,13 +411,23 @@ func ParseSAUCE(data []byte, encoding string) (*SAUCE, string, error) {
 		sauce.TInfo3.Name = TInfoNameNone
 		sauce.TInfo4.Name = TInfoNameNone
 	}
-	strData, err := parse.DecodeFileContents(data[:eofIdx], encoding)
-	if err != nil {
-		return nil, "", fmt.Errorf("error decoding file data: %v", err)
-	}
+	return sauce, eofIdx, nil
+}
 
-	// Return the data without the EOF marker and SAUCE record
-	return sauce, strData, nil
+// parseSAUCEComments reads the COMNT block of nLines 64 byte lines from the end of the data,
+// returning the (trimmed) lines decoded from CP437 and the index of the start of the block.
+// ok is false if the data does not end with a valid comment block.
+func parseSAUCEComments(data []byte, nLines int) (lines []string, commentIdx int, ok bool) {
+	commentIdx = len(data) - 5 - 64*nLines
+	if commentIdx < 0 || string(data[commentIdx:commentIdx+5]) != "COMNT" {
+		return nil, 0, false
+	}
+	lines = make([]string, nLines)
+	for i := range nLines {
+		lineIdx := commentIdx + 5 + 64*i
+		lines[i] = sauceString(data[lineIdx : lineIdx+64])
+	}
+	return lines, commentIdx, true
 }
 
 func SAUCERecord(data []byte, encoding string) (*SAUCE, string, error) {
@@ -422,6 +473,96 @@ func (s *SAUCE) IsANSIFile() bool {
 	return s.DataType == DataTypeCharacter && s.FileType == FileTypeCharacterANSI
 }
 
+// SetDate sets the date of the record, which must be in CCYYMMDD format
+func (s *SAUCE) SetDate(date string) error {
+	if _, err := time.Parse("20060102", date); err != nil {
+		return fmt.Errorf("invalid SAUCE date %q, expected CCYYMMDD format", date)
+	}
+	s.Date = date
+	return nil
+}
+
+// ToBytes serialises the SAUCE record so that it can be appended to the file data.
+// The result starts with the EOF marker ('\x1a'), followed by the COMNT block
+// (if there are any comment lines), and then the 128 byte SAUCE record.
+// Strings that are too long for their field are truncated, and the Comments count is written from the
+// number of comment lines (at most 255).
+func (s *SAUCE) ToBytes() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteByte('\x1a')
+
+	nComments := min(len(s.CommentLines), 255)
+	if nComments > 0 {
+		buf.WriteString("COMNT")
+		for _, line := range s.CommentLines[:nComments] {
+			buf.Write(sauceField(line, 64, ' '))
+		}
+	}
+
+	version := s.Version
+	if version == "" {
+		version = "00"
+	}
+	buf.WriteString("SAUCE")                 // ID (5 bytes)
+	buf.Write(sauceField(version, 2, '0'))   // Version (2 bytes)
+	buf.Write(sauceField(s.Title, 35, ' '))  // Title (35 bytes)
+	buf.Write(sauceField(s.Author, 20, ' ')) // Author (20 bytes)
+	buf.Write(sauceField(s.Group, 20, ' '))  // Group (20 bytes)
+	buf.Write(sauceField(s.Date, 8, ' '))    // Date (8 bytes)
+
+	binary.Write(&buf, binary.LittleEndian, s.FileSize)     // FileSize (4 bytes, little-endian unsigned)
+	binary.Write(&buf, binary.LittleEndian, s.DataType)     // DataType (1 byte)
+	binary.Write(&buf, binary.LittleEndian, s.FileType)     // FileType (1 byte)
+	binary.Write(&buf, binary.LittleEndian, s.TInfo1.Value) // TInfo1 (2 bytes, little-endian)
+	binary.Write(&buf, binary.LittleEndian, s.TInfo2.Value) // TInfo2 (2 bytes, little-endian)
+	binary.Write(&buf, binary.LittleEndian, s.TInfo3.Value) // TInfo3 (2 bytes, little-endian)
+	binary.Write(&buf, binary.LittleEndian, s.TInfo4.Value) // TInfo4 (2 bytes, little-endian)
+	buf.WriteByte(byte(nComments))                          // Comments (1 byte)
+	buf.WriteByte(s.TFlags)                                 // TFlags (1 byte)
+	buf.Write(sauceField(s.TInfoS, 22, 0))                  // TInfoS (22 bytes) - null-padded string
+
+	return buf.Bytes()
+}
+
+// WriteSAUCE returns the file data with its SAUCE record replaced (or appended if there was none).
+// The file data itself is left untouched, the FileSize of the record is updated to match it,
+// and the comment lines are limited to 255, with Comments updated to match them.
+func WriteSAUCE(data []byte, sauce *SAUCE) []byte {
+	fileData := StripSAUCE(data)
+	sauce.FileSize = uint32(len(fileData))
+	sauce.CommentLines = sauce.CommentLines[:min(len(sauce.CommentLines), 255)]
+	sauce.Comments = byte(len(sauce.CommentLines))
+
+	result := make([]byte, 0, len(fileData)+129)
+	result = append(result, fileData...)
+	return append(result, sauce.ToBytes()...)
+}
+
+// sauceField encodes a string as a fixed-width SAUCE field, truncating or padding it as needed.
+// UTF-8 strings are encoded as CP437 where possible, otherwise the raw bytes are used.
+func sauceField(s string, width int, pad byte) []byte {
+	field := []byte(s)
+	if utf8.ValidString(s) {
+		if encoded, err := charmap.CodePage437.NewEncoder().Bytes([]byte(s)); err == nil {
+			field = encoded
+		}
+	}
+	if len(field) > width {
+		field = field[:width]
+	}
+	return append(field, bytes.Repeat([]byte{pad}, width-len(field))...)
+}
+
+// sauceString decodes a fixed-width SAUCE field from CP437 (the reverse of sauceField), trimming the padding
+func sauceString(field []byte) string {
+	field = bytes.TrimRight(field, " \x00")
+	if decoded, err := charmap.CodePage437.NewDecoder().Bytes(field); err == nil {
+		return string(decoded)
+	}
+	return string(field)
+}
+
 func (s *SAUCE) ToJSON() (string, error) {
 	jsonBytes, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
@@ -470,7 +611,10 @@ func (s *SAUCE) ToString() string {
 		sb.WriteString(fmt.Sprintf(fmtStr, cYellow, "  Name:", cReset, s.TInfo4.Name))
 		sb.WriteString(fmt.Sprintf(fmtStr, cYellow, "  Value:", cReset, s.TInfo4.Value))
 	}
-	sb.WriteString(fmt.Sprintf(fmtStr, cCyan, "Comments:", cReset, string(s.Comments)))
+	sb.WriteString(fmt.Sprintf(fmtStr, cCyan, "Comments:", cReset, s.Comments))
+	for _, line := range s.CommentLines {
+		sb.WriteString(fmt.Sprintf(fmtStr, cCyan, "", cReset, fmt.Sprintf("%s%s%s", cItalic, line, cReset)))
+	}
 	sb.WriteString(fmt.Sprintf(fmtStr, cCyan, "TFlags:", cReset, string(s.TFlags)))
 	sb.WriteString(fmt.Sprintf(fmtStr, cCyan, "TInfoS:", cReset, s.TInfoS))
 	return sb.String()
@@ -0,0 +1,207 @@
+package convert
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Scale enlarges tokenized ANSI lines by repeating each cell factor times horizontally and vertically.
+// Double-width runes are repeated as a whole, so they keep their shape.
+func Scale(lines [][]ANSILineToken, factor int) ([][]ANSILineToken, error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("invalid scale %d, must be at least 1", factor)
+	}
+	grid := TokensToCells(lines)
+	scaled := make([][]Cell, 0, len(grid)*factor)
+	for _, row := range grid {
+		scaledRow := make([]Cell, 0, len(row)*factor)
+		for x := 0; x < len(row); x++ {
+			// a double-width rune & its continuation cell are repeated together
+			cells := row[x : x+1]
+			if row[x].R != 0 && runeWidth(row[x].R) == 2 && x+1 < len(row) {
+				cells = row[x : x+2]
+				x++
+			}
+			for range factor {
+				scaledRow = append(scaledRow, cells...)
+			}
+		}
+		for range factor {
+			scaled = append(scaled, scaledRow)
+		}
+	}
+	return CellsToTokens(scaled), nil
+}
+
+// DownscaleMode is the number of cells that are combined into each cell when downscaling
+type DownscaleMode int
+
+const (
+	// DownscaleHalfBlocks combines each 1x2 (w x h) block of cells into a single half block cell (▀, ▄)
+	DownscaleHalfBlocks DownscaleMode = iota
+	// DownscaleQuadrants combines each 2x2 block of cells into a single quadrant block cell (▘, ▚, ▌, ▙, etc.)
+	DownscaleQuadrants
+)
+
+// quadrantGlyphs are the block glyphs for each combination of filled quadrants,
+// where the bits of the index are top left (1), top right (2), bottom left (4) & bottom right (8)
+var quadrantGlyphs = [16]rune{' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛', '▗', '▚', '▐', '▜', '▄', '▙', '▟', '█'}
+
+// quadrantMasks maps each quadrant block glyph to its filled quadrants (see quadrantGlyphs)
+var quadrantMasks = func() map[rune]int {
+	masks := make(map[rune]int, len(quadrantGlyphs))
+	for mask, r := range quadrantGlyphs {
+		masks[r] = mask
+	}
+	return masks
+}()
+
+// pixel is the colour that a cell appears to be from a distance.
+// The default foreground & background colours are kept apart, as they are different colours.
+type pixel struct {
+	colour     Colour
+	background bool // only used for the default colours
+}
+
+// cellPixel returns the colour of a cell: the foreground if its glyph covers at least half of the cell,
+// otherwise the background. Bold makes the first 8 foreground colours bright, as on DOS.
+func cellPixel(cell Cell) pixel {
+	style := ParseStyle(cell.FG + cell.BG)
+	filled := true
+	switch cell.R {
+	case ' ', 0, ' ', '░':
+		filled = false
+	default:
+		if mask, ok := quadrantMasks[cell.R]; ok {
+			filled = bits.OnesCount(uint(mask)) >= 2
+		}
+	}
+	if style.Reverse {
+		filled = !filled
+	}
+	if !filled {
+		if style.BG.Kind == ColourDefault {
+			return pixel{background: true}
+		}
+		return pixel{colour: style.BG}
+	}
+	if style.Bold && style.FG.Kind == ColourIndexed && style.FG.Index < 8 {
+		style.FG.Index += 8
+	}
+	return pixel{colour: style.FG}
+}
+
+// code returns the SGR code that draws the pixel as a foreground (or background) colour,
+// or "" for the matching default colour
+func (p pixel) code(background bool) string {
+	c := p.colour
+	if c.Kind == ColourDefault {
+		if p.background == background {
+			return ""
+		}
+		// e.g. the default background drawn as a foreground
+		c = IndexedColour(defaultColourIndex(p.background))
+	}
+	return "\x1b[" + c.params(background) + "m"
+}
+
+// rgb returns the 24-bit value of the pixel, using the VGA palette
+func (p pixel) rgb() RGB {
+	if p.colour.Kind == ColourIndexed && p.colour.Index < 16 {
+		return VGAPalette[p.colour.Index]
+	}
+	return p.colour.ToRGB(defaultColourIndex(p.background))
+}
+
+// defaultColourIndex returns the palette index of the default foreground (light grey) or background (black)
+func defaultColourIndex(background bool) uint8 {
+	if background {
+		return 0
+	}
+	return 7
+}
+
+// Downscale shrinks tokenized ANSI lines by combining blocks of cells into half block or quadrant block glyphs
+// (see DownscaleMode), so that each cell becomes one half or quarter of a cell.
+// Each cell is treated as a single colour (see cellPixel), and when a block has more than 2 colours,
+// the 2 most common are used, with the others drawn as whichever of those is nearest.
+// Odd sized art is padded with the default background colour.
+func Downscale(lines [][]ANSILineToken, mode DownscaleMode) [][]ANSILineToken {
+	grid := TokensToCells(lines)
+	blockWidth := 1
+	if mode == DownscaleQuadrants {
+		blockWidth = 2
+	}
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	at := func(x, y int) pixel {
+		if y >= len(grid) || x >= len(grid[y]) {
+			return pixel{background: true}
+		}
+		if grid[y][x].R == 0 && x > 0 {
+			// the continuation of a double-width rune has the colour of the rune
+			return cellPixel(grid[y][x-1])
+		}
+		return cellPixel(grid[y][x])
+	}
+
+	downscaled := make([][]Cell, 0, (len(grid)+1)/2)
+	for y := 0; y < len(grid); y += 2 {
+		row := make([]Cell, 0, (width+blockWidth-1)/blockWidth)
+		for x := 0; x < width; x += blockWidth {
+			// the pixels in the order of the quadrant bits: top left, top right, bottom left & bottom right
+			var pixels [4]pixel
+			if mode == DownscaleQuadrants {
+				pixels = [4]pixel{at(x, y), at(x+1, y), at(x, y+1), at(x+1, y+1)}
+			} else {
+				pixels = [4]pixel{at(x, y), at(x, y), at(x, y+1), at(x, y+1)}
+			}
+			row = append(row, blockCell(pixels))
+		}
+		downscaled = append(downscaled, row)
+	}
+	return CellsToTokens(downscaled)
+}
+
+// blockCell returns the quadrant block cell that draws 4 pixels (see quadrantGlyphs)
+func blockCell(pixels [4]pixel) Cell {
+	// find the 2 most common colours, in order of first appearance
+	counts := make(map[pixel]int, 4)
+	order := make([]pixel, 0, 4)
+	for _, p := range pixels {
+		if counts[p] == 0 {
+			order = append(order, p)
+		}
+		counts[p]++
+	}
+	fg, bg := order[0], order[0]
+	for _, p := range order[1:] {
+		if counts[p] > counts[fg] {
+			fg, bg = p, fg
+		} else if bg == fg || counts[p] > counts[bg] {
+			bg = p
+		}
+	}
+
+	if fg == bg {
+		// a single colour, drawn as a space, unless it can only be a foreground colour
+		if fg.colour.Kind == ColourDefault && !fg.background {
+			return Cell{FG: fg.code(false), BG: "", R: '█'}
+		}
+		return Cell{FG: "", BG: bg.code(true), R: ' '}
+	}
+	// keep the default colours in their usual place where possible
+	if (fg.colour.Kind == ColourDefault && fg.background) || (bg.colour.Kind == ColourDefault && !bg.background) {
+		fg, bg = bg, fg
+	}
+
+	mask := 0
+	for i, p := range pixels {
+		if p == fg || (p != bg && euclideanDistance(p.rgb(), fg.rgb()) < euclideanDistance(p.rgb(), bg.rgb())) {
+			mask |= 1 << i
+		}
+	}
+	return Cell{FG: fg.code(false), BG: bg.code(true), R: quadrantGlyphs[mask]}
+}
@@ -0,0 +1,273 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ColourKind is the type of a foreground or background colour
+type ColourKind int
+
+const (
+	// ColourDefault is the terminal's default colour (39 & 49)
+	ColourDefault ColourKind = iota
+	// ColourIndexed is a colour in the 256 colour palette, where 0-15 are the 16 ANSI colours (30-37 & 90-97)
+	ColourIndexed
+	// ColourRGB is a 24-bit truecolor value (38;2;R;G;B)
+	ColourRGB
+)
+
+// Colour is a foreground or background colour
+type Colour struct {
+	Kind  ColourKind
+	Index uint8
+	RGB   RGB
+}
+
+// IndexedColour returns a colour from the 256 colour palette
+func IndexedColour(index uint8) Colour {
+	return Colour{Kind: ColourIndexed, Index: index}
+}
+
+// RGBColour returns a 24-bit colour
+func RGBColour(r, g, b uint8) Colour {
+	return Colour{Kind: ColourRGB, RGB: RGB{r, g, b}}
+}
+
+// ToRGB returns the 24-bit value of the colour, using the VGA palette for the 16 ANSI colours,
+// and the given palette index for the default colour
+func (c Colour) ToRGB(defaultIndex uint8) RGB {
+	switch c.Kind {
+	case ColourIndexed:
+		return XtermColour(c.Index)
+	case ColourRGB:
+		return c.RGB
+	default:
+		return XtermColour(defaultIndex)
+	}
+}
+
+// params returns the SGR parameters that select the colour, e.g. "31", "38;5;208" or "48;2;255;0;0"
+func (c Colour) params(background bool) string {
+	switch {
+	case c.Kind == ColourIndexed && c.Index < 16:
+		return ansiCode(int(c.Index), background)
+	case c.Kind == ColourIndexed && background:
+		return "48;5;" + strconv.Itoa(int(c.Index))
+	case c.Kind == ColourIndexed:
+		return "38;5;" + strconv.Itoa(int(c.Index))
+	case c.Kind == ColourRGB:
+		return trueColourCode(c.RGB, background)
+	case background:
+		return "49"
+	default:
+		return "39"
+	}
+}
+
+// Code returns the SGR code that selects the colour, e.g. "\x1b[31m" or "\x1b[48;2;255;0;0m",
+// or "" for the default colour
+func (c Colour) Code(background bool) string {
+	if c.Kind == ColourDefault {
+		return ""
+	}
+	return "\x1b[" + c.params(background) + "m"
+}
+
+// Style is the full set of SGR attributes that apply to a piece of text
+type Style struct {
+	FG        Colour
+	BG        Colour
+	Bold      bool
+	Dim       bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+	Conceal   bool
+	Strike    bool
+}
+
+// ParseStyle parses all of the SGR codes in a string (e.g. a token's FG + BG) into a Style,
+// starting from the default style, e.g. "\x1b[1m\x1b[38;2;224;224;224m" is bold with a truecolor foreground.
+// Any non-SGR escape sequences are ignored.
+func ParseStyle(codes string) Style {
+	return Style{}.Apply(codes)
+}
+
+// Style returns the style of a token, parsed from its FG & BG codes
+func (t ANSILineToken) Style() Style {
+	return ParseStyle(t.FG + t.BG)
+}
+
+// Apply returns the style after applying all of the SGR codes in a string
+func (s Style) Apply(codes string) Style {
+	for _, code := range strings.Split(codes, "\x1b[")[1:] {
+		if !strings.HasSuffix(code, "m") {
+			continue
+		}
+		params, ok := parseSGRParams(strings.TrimSuffix(code, "m"))
+		if !ok {
+			continue
+		}
+		s = s.ApplySGR(params)
+	}
+	return s
+}
+
+// parseSGRParams splits the parameters of an SGR code, e.g. "38;5;129" -> [38 5 129].
+// Missing parameters are 0, so that "\x1b[m" is a reset.
+func parseSGRParams(s string) ([]int, bool) {
+	parts := strings.Split(s, ";")
+	params := make([]int, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		p, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		params[i] = p
+	}
+	return params, true
+}
+
+// ApplySGR returns the style after applying the parameters of a single SGR code, e.g. [0 1 31]
+func (s Style) ApplySGR(params []int) Style {
+	for _, attr := range sgrAttributes(params) {
+		switch p := attr.param; {
+		case attr.isColour && attr.background:
+			s.BG = attr.colour
+		case attr.isColour:
+			s.FG = attr.colour
+		case p == 0:
+			s = Style{}
+		case p == 1:
+			s.Bold = true
+		case p == 2:
+			s.Dim = true
+		case p == 3:
+			s.Italic = true
+		case p == 4:
+			s.Underline = true
+		case p == 5 || p == 6:
+			s.Blink = true
+		case p == 7:
+			s.Reverse = true
+		case p == 8:
+			s.Conceal = true
+		case p == 9:
+			s.Strike = true
+		case p == 22:
+			s.Bold, s.Dim = false, false
+		case p == 23:
+			s.Italic = false
+		case p == 24:
+			s.Underline = false
+		case p == 25:
+			s.Blink = false
+		case p == 27:
+			s.Reverse = false
+		case p == 28:
+			s.Conceal = false
+		case p == 29:
+			s.Strike = false
+		}
+	}
+	return s
+}
+
+// sgrAttribute is a single attribute of an SGR code, e.g. the 1 of "1;31", with the colour that it selects
+// if it is a colour code (30-39, 40-49, 90-97 & 100-107, where 38 & 48 include the parameters of their colour)
+type sgrAttribute struct {
+	param      int
+	colour     Colour
+	isColour   bool
+	background bool
+}
+
+// sgrAttributes splits the parameters of a single SGR code into attributes, e.g. [1 38 5 129] -> 1 & 38;5;129.
+// 38 & 48 codes with a missing or invalid colour are dropped, along with the parameters that follow them.
+func sgrAttributes(params []int) []sgrAttribute {
+	attrs := make([]sgrAttribute, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		attr := sgrAttribute{param: params[i]}
+		switch p := params[i]; {
+		case p >= 30 && p <= 37, p >= 90 && p <= 97:
+			attr.colour, attr.isColour = IndexedColour(uint8(ansiIndex(p))), true
+		case p >= 40 && p <= 47, p >= 100 && p <= 107:
+			attr.colour, attr.isColour, attr.background = IndexedColour(uint8(ansiIndex(p))), true, true
+		case p == 39 || p == 49:
+			attr.isColour, attr.background = true, p == 49
+		case p == 38 || p == 48:
+			c, n, ok := sgrExtendedColour(params[i+1:])
+			i += n
+			if !ok {
+				continue
+			}
+			attr.colour, attr.isColour, attr.background = c, true, p == 48
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+// sgrExtendedColour reads the parameters following a 38/48 code ("5;N" or "2;R;G;B"),
+// returning the colour and the number of parameters consumed.
+// The colour isn't valid if it is missing, or a value is outside of 0-255, in which case all of the parameters are consumed.
+func sgrExtendedColour(params []int) (Colour, int, bool) {
+	inRange := func(values []int) bool {
+		for _, v := range values {
+			if v < 0 || v > 255 {
+				return false
+			}
+		}
+		return true
+	}
+	if len(params) >= 2 && params[0] == 5 && inRange(params[1:2]) {
+		return IndexedColour(uint8(params[1])), 2, true
+	}
+	if len(params) >= 4 && params[0] == 2 && inRange(params[1:4]) {
+		return RGBColour(uint8(params[1]), uint8(params[2]), uint8(params[3])), 4, true
+	}
+	return Colour{}, len(params), false
+}
+
+// Params returns the minimal SGR parameters that select the style from the default style,
+// e.g. "1;31;44", or "" for the default style
+func (s Style) Params() string {
+	params := make([]string, 0)
+	for _, attr := range []struct {
+		set   bool
+		param string
+	}{
+		{s.Bold, "1"}, {s.Dim, "2"}, {s.Italic, "3"}, {s.Underline, "4"},
+		{s.Blink, "5"}, {s.Reverse, "7"}, {s.Conceal, "8"}, {s.Strike, "9"},
+	} {
+		if attr.set {
+			params = append(params, attr.param)
+		}
+	}
+	if s.FG.Kind != ColourDefault {
+		params = append(params, s.FG.params(false))
+	}
+	if s.BG.Kind != ColourDefault {
+		params = append(params, s.BG.params(true))
+	}
+	return strings.Join(params, ";")
+}
+
+// String returns a single SGR code that selects the style from any other style,
+// e.g. "\x1b[0;1;31;44m", or "\x1b[0m" for the default style
+func (s Style) String() string {
+	if params := s.Params(); params != "" {
+		return "\x1b[0;" + params + "m"
+	}
+	return "\x1b[0m"
+}
+
+// IsDefault returns true if the style has no attributes and the default colours
+func (s Style) IsDefault() bool {
+	return s == Style{}
+}
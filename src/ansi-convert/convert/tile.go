@@ -0,0 +1,131 @@
+package convert
+
+import (
+	"strings"
+)
+
+// Alignment is the position of an image within a tile that is larger than the image
+type Alignment int
+
+const (
+	// AlignStart aligns images to the top or left of their tile
+	AlignStart Alignment = iota
+	// AlignCentre centres images within their tile
+	AlignCentre
+	// AlignEnd aligns images to the bottom or right of their tile
+	AlignEnd
+)
+
+// TileOptions controls how images are laid out by Tile
+//   - Columns is the number of images in each row, where 0 puts all of the images in a single row,
+//     and 1 puts them in a single column
+//   - Padding is the number of blank columns (or lines) on each side of the gap between tiles
+//   - Separator is drawn between columns, and RowSeparator is repeated across the full width between rows.
+//     Both are drawn in the default colours.
+//   - HAlign & VAlign align each image within its tile, which is as wide as the widest image in its column,
+//     and as tall as the tallest image in its row
+type TileOptions struct {
+	Columns      int
+	Padding      int
+	Separator    string
+	RowSeparator string
+	HAlign       Alignment
+	VAlign       Alignment
+}
+
+// Tile lays out tokenized images in a row, a column, or a grid (see TileOptions), returning a single image.
+// Colours never leak from one image into the padding, separators or other images.
+func Tile(images [][][]ANSILineToken, opts TileOptions) [][]ANSILineToken {
+	if len(images) == 0 {
+		return [][]ANSILineToken{}
+	}
+	columns := opts.Columns
+	if columns <= 0 || columns > len(images) {
+		columns = len(images)
+	}
+	rows := (len(images) + columns - 1) / columns
+
+	grids := make([][][]Cell, len(images))
+	widths, heights := make([]int, columns), make([]int, rows)
+	for i, image := range images {
+		grids[i] = TokensToCells(image)
+		heights[i/columns] = max(heights[i/columns], len(grids[i]))
+		for _, row := range grids[i] {
+			widths[i%columns] = max(widths[i%columns], len(row))
+		}
+	}
+
+	padding := strings.Repeat(" ", max(opts.Padding, 0))
+	gap := textCells(padding + opts.Separator + padding)
+	totalWidth := len(gap) * (columns - 1)
+	for _, w := range widths {
+		totalWidth += w
+	}
+
+	tiled := make([][]Cell, 0)
+	for r := range rows {
+		if r > 0 {
+			for range opts.Padding {
+				tiled = append(tiled, blankCells(totalWidth))
+			}
+			if separator := textCells(opts.RowSeparator); len(separator) > 0 {
+				tiled = append(tiled, repeatCells(separator, totalWidth))
+			}
+			for range opts.Padding {
+				tiled = append(tiled, blankCells(totalWidth))
+			}
+		}
+		for y := range heights[r] {
+			line := make([]Cell, 0, totalWidth)
+			for c := range columns {
+				if c > 0 {
+					line = append(line, gap...)
+				}
+				tile := blankCells(widths[c])
+				if i := r*columns + c; i < len(grids) {
+					grid := grids[i]
+					if row := y - alignOffset(heights[r]-len(grid), opts.VAlign); row >= 0 && row < len(grid) {
+						copy(tile[alignOffset(widths[c]-len(grid[row]), opts.HAlign):], grid[row])
+					}
+				}
+				line = append(line, tile...)
+			}
+			tiled = append(tiled, line)
+		}
+	}
+	return CellsToTokens(tiled)
+}
+
+// alignOffset returns the offset of an image within a tile, given the free space in the tile
+func alignOffset(free int, align Alignment) int {
+	switch align {
+	case AlignCentre:
+		return free / 2
+	case AlignEnd:
+		return free
+	default:
+		return 0
+	}
+}
+
+// textCells returns the cells of a string drawn in the default colours
+func textCells(s string) []Cell {
+	return TokensToCells([][]ANSILineToken{{{FG: "", BG: "", T: s}}})[0]
+}
+
+// blankCells returns a row of spaces in the default colours
+func blankCells(width int) []Cell {
+	return repeatCells([]Cell{{R: ' '}}, width)
+}
+
+// repeatCells repeats a pattern of cells to fill a row, replacing a double-width rune cut off at the end with a space
+func repeatCells(pattern []Cell, width int) []Cell {
+	row := make([]Cell, width)
+	for i := range row {
+		row[i] = pattern[i%len(pattern)]
+	}
+	if width > 0 && row[width-1].R != 0 && runeWidth(row[width-1].R) == 2 {
+		row[width-1].R = ' '
+	}
+	return row
+}
@@ -0,0 +1,181 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// TundraID is the header at the start of every TundraDraw file: the version (24), followed by "TUNDRA24"
+const TundraID = "\x18TUNDRA24"
+
+// TundraWidth is the width of every TundraDraw file, which wraps at 80 columns
+const TundraWidth = 80
+
+// TundraMaxHeight is the most lines that a TundraDraw position can move to, the most that a SAUCE record can describe
+const TundraMaxHeight = 0xffff
+
+// TundraDraw opcodes (see DecodeTundra)
+const (
+	tundraPosition   byte = 0x01
+	tundraForeground byte = 0x02
+	tundraBackground byte = 0x04
+	tundraColours    byte = 0x06
+)
+
+// DecodeTundra converts TundraDraw (.TND) data (without a SAUCE record) to tokenized ANSI lines,
+// with truecolor foreground & background colours.
+// After the header, each byte is a CP437 character drawn with the current colours, or one of the opcodes:
+//   - 0x01 <row> <column> moves the cursor to a position (from 0)
+//   - 0x02 <char> <fg> draws a character after setting the foreground colour
+//   - 0x04 <char> <bg> draws a character after setting the background colour
+//   - 0x06 <char> <fg> <bg> draws a character after setting both colours
+//
+// Positions & colours are 4 byte big endian numbers, where colours are 0x00RRGGBB.
+// Characters drawn before a colour is set use the default colours, and rows that are skipped by a position are empty.
+func DecodeTundra(data []byte) ([][]ANSILineToken, error) {
+	if !bytes.HasPrefix(data, []byte(TundraID)) {
+		return nil, fmt.Errorf("no valid TundraDraw header found")
+	}
+	data = data[len(TundraID):]
+
+	grid := make([][]Cell, 0)
+	fg, bg := "", ""
+	x, y := 0, 0
+	for i := 0; i < len(data); i++ {
+		char := data[i]
+		// the number of bytes that the opcode reads after the character
+		need := 0
+		switch char {
+		case tundraPosition:
+			need = 8
+		case tundraForeground, tundraBackground:
+			need = 5
+		case tundraColours:
+			need = 9
+		}
+		if need > 0 && i+need >= len(data) {
+			return nil, fmt.Errorf("TundraDraw data too short, expected %d bytes after opcode 0x%02x at %d", need, char, i+len(TundraID))
+		}
+
+		switch char {
+		case tundraPosition:
+			y = int(binary.BigEndian.Uint32(data[i+1 : i+5]))
+			x = int(binary.BigEndian.Uint32(data[i+5 : i+9]))
+			if x >= TundraWidth || y >= TundraMaxHeight {
+				return nil, fmt.Errorf("invalid TundraDraw position %d,%d at %d", y, x, i+len(TundraID))
+			}
+			i += need
+			continue
+		case tundraForeground:
+			fg = "\x1b[" + trueColourCode(tundraColour(data[i+2:i+6]), false) + "m"
+		case tundraBackground:
+			bg = "\x1b[" + trueColourCode(tundraColour(data[i+2:i+6]), true) + "m"
+		case tundraColours:
+			fg = "\x1b[" + trueColourCode(tundraColour(data[i+2:i+6]), false) + "m"
+			bg = "\x1b[" + trueColourCode(tundraColour(data[i+6:i+10]), true) + "m"
+		}
+		if need > 0 {
+			char = data[i+1]
+			i += need
+		}
+
+		if x >= TundraWidth {
+			x, y = 0, y+1
+		}
+		// rows are only filled in when something is drawn on them, so that a position far down the
+		// file doesn't allocate the rows that it skips
+		for len(grid) <= y {
+			grid = append(grid, nil)
+		}
+		if grid[y] == nil {
+			grid[y] = make([]Cell, TundraWidth)
+			for j := range grid[y] {
+				grid[y][j] = Cell{R: ' '}
+			}
+		}
+		grid[y][x] = Cell{FG: fg, BG: bg, R: CP437Rune(char)}
+		x++
+	}
+	return CellsToTokens(grid), nil
+}
+
+// tundraStyleColours returns the foreground & background colours of a style, with bold & blink as high intensity
+// colours (see EncodeTundra)
+func tundraStyleColours(style Style, iceColour bool) (RGB, RGB) {
+	fg, bg := style.FG, style.BG
+	if style.Bold && (fg.Kind == ColourDefault || (fg.Kind == ColourIndexed && fg.Index < 8)) {
+		fg = IndexedColour(vgaIndex(fg, 7) + 8)
+	}
+	if iceColour && style.Blink && (bg.Kind == ColourDefault || (bg.Kind == ColourIndexed && bg.Index < 8)) {
+		bg = IndexedColour(vgaIndex(bg, 0) + 8)
+	}
+	fgRGB, bgRGB := fg.ToRGB(7), bg.ToRGB(0)
+	if style.Reverse {
+		fgRGB, bgRGB = bgRGB, fgRGB
+	}
+	return fgRGB, bgRGB
+}
+
+// tundraColour returns the colour of a 4 byte TundraDraw colour (0x00RRGGBB)
+func tundraColour(b []byte) RGB {
+	return RGB{b[1], b[2], b[3]}
+}
+
+// EncodeTundra converts tokenized ANSI lines to TundraDraw (.TND) data (see DecodeTundra), with the header.
+// Truecolor colours are kept, and other colours are converted to RGB, where the default colours are
+// light grey on black. Bold makes the first 8 foreground colours bright as on DOS (see textAttribute), and with
+// iCE colour, blink makes the first 8 background colours bright, otherwise it is dropped.
+// The colours are only written when they change, and lines that are shorter than 80 columns end with
+// a cursor movement to the next line.
+// Runes that aren't in code page 437 are written as '?'.
+func EncodeTundra(lines [][]ANSILineToken, iceColour bool) ([]byte, error) {
+	grid := TokensToCells(lines)
+	for _, row := range grid {
+		if len(row) > TundraWidth {
+			return nil, fmt.Errorf("invalid width %d, TundraDraw files are %d columns wide", len(row), TundraWidth)
+		}
+	}
+
+	data := []byte(TundraID)
+	colour := func(c RGB) []byte {
+		return []byte{0, c.R, c.G, c.B}
+	}
+	var fg, bg RGB
+	started := false
+	for y, row := range grid {
+		for _, cell := range row {
+			char := byte(' ')
+			if cell.R != 0 {
+				char, _ = CP437Byte(cell.R)
+			}
+			cellFG, cellBG := tundraStyleColours(ParseStyle(cell.FG+cell.BG), iceColour)
+
+			switch {
+			case !started || (cellFG != fg && cellBG != bg):
+				data = append(data, tundraColours, char)
+				data = append(data, colour(cellFG)...)
+				data = append(data, colour(cellBG)...)
+			case cellFG != fg:
+				data = append(data, tundraForeground, char)
+				data = append(data, colour(cellFG)...)
+			case cellBG != bg:
+				data = append(data, tundraBackground, char)
+				data = append(data, colour(cellBG)...)
+			case char == tundraPosition || char == tundraForeground || char == tundraBackground || char == tundraColours:
+				// characters with the same value as an opcode can only be drawn by an opcode
+				data = append(data, tundraForeground, char)
+				data = append(data, colour(cellFG)...)
+			default:
+				data = append(data, char)
+			}
+			fg, bg, started = cellFG, cellBG, true
+		}
+		if len(row) < TundraWidth && y < len(grid)-1 {
+			data = append(data, tundraPosition)
+			data = binary.BigEndian.AppendUint32(data, uint32(y+1))
+			data = binary.BigEndian.AppendUint32(data, 0)
+		}
+	}
+	return data, nil
+}
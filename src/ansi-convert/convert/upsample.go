@@ -0,0 +1,80 @@
+package convert
+
+import "fmt"
+
+// UpsampleColours rewrites the colours of every token as explicit truecolor codes ("38;2;R;G;B" & "48;2;R;G;B"),
+// so that the output looks the same regardless of the terminal's palette.
+//   - the 16 ANSI colours (30-37, 90-97, 40-47 & 100-107) and the first 16 of the 256 colours use the given palette
+//   - bold makes the first 8 foreground colours bright, as on DOS
+//   - the default colours (no colour code, 39 & 49, or after a reset) become palette colours 7 (light grey) & 0 (black),
+//     or 15 (white) for a bold foreground
+//   - the rest of the 256 colours use the xterm colour cube & greyscale ramp
+//
+// Each token's FG starts with a reset followed by its other attributes (e.g. bold), so that it doesn't depend on
+// the tokens before it.
+func UpsampleColours(lines [][]ANSILineToken, palette Palette) [][]ANSILineToken {
+	u := upsampler{palette: palette, cache: make(map[[2]string]ANSILineToken)}
+
+	upsampled := make([][]ANSILineToken, len(lines))
+	for i, tokens := range lines {
+		upsampled[i] = make([]ANSILineToken, len(tokens))
+		for j, token := range tokens {
+			upsampled[i][j] = u.rewrite(token)
+		}
+	}
+	return upsampled
+}
+
+// upsampler holds the target palette, and a cache of the FG & BG codes that have already been rewritten
+type upsampler struct {
+	palette Palette
+	cache   map[[2]string]ANSILineToken
+}
+
+// rewrite upsamples the style of a token, parsed from its FG & BG codes
+func (u upsampler) rewrite(token ANSILineToken) ANSILineToken {
+	key := [2]string{token.FG, token.BG}
+	if rewritten, ok := u.cache[key]; ok {
+		rewritten.T = token.T
+		return rewritten
+	}
+	style := token.Style()
+
+	// bold brightens the foreground regardless of which code it was set by, as the style is parsed from all of them
+	fg := u.colour(style.FG, 7)
+	if style.Bold && style.FG.Kind == ColourDefault {
+		fg = u.palette[15]
+	} else if style.Bold && style.FG.Kind == ColourIndexed && style.FG.Index < 8 {
+		fg = u.palette[style.FG.Index+8]
+	}
+	bg := u.colour(style.BG, 0)
+
+	attrs := style
+	attrs.FG, attrs.BG = RGBColour(fg.R, fg.G, fg.B), Colour{}
+	u.cache[key] = ANSILineToken{FG: attrs.String(), BG: "\x1b[" + trueColourCode(bg, true) + "m"}
+
+	rewritten := u.cache[key]
+	rewritten.T = token.T
+	return rewritten
+}
+
+// colour returns the truecolor value of a colour, using the palette for the first 16 colours & the default colour
+func (u upsampler) colour(c Colour, defaultIndex uint8) RGB {
+	switch {
+	case c.Kind == ColourDefault:
+		return u.palette[defaultIndex]
+	case c.Kind == ColourIndexed && c.Index < 16:
+		return u.palette[c.Index]
+	default:
+		return c.ToRGB(defaultIndex)
+	}
+}
+
+// trueColourCode returns the "38;2;R;G;B" (or "48;2;R;G;B") parameters for a colour
+func trueColourCode(c RGB, background bool) string {
+	prefix := 38
+	if background {
+		prefix = 48
+	}
+	return fmt.Sprintf("%d;2;%d;%d;%d", prefix, c.R, c.G, c.B)
+}
@@ -0,0 +1,179 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// XBinID is the signature at the start of every XBin file
+const XBinID = "XBIN\x1a"
+
+// XBin header flag bit masks
+const (
+	XBinFlagPalette  byte = 0x01 // a 16 colour palette follows the header
+	XBinFlagFont     byte = 0x02 // a font follows the header (and palette)
+	XBinFlagCompress byte = 0x04 // the image data is compressed
+	XBinFlagNonBlink byte = 0x08 // iCE colour: bit 7 of the attribute is a high intensity background
+	XBinFlag512Chars byte = 0x10 // the font has 512 characters, selected by bit 3 of the attribute
+)
+
+// XBin is a parsed XBin (.XB) file
+//   - Width & Height are the size of the image in characters
+//   - FontHeight is the number of rows in each glyph of the font (1-32)
+//   - Palette is the embedded palette, or nil if the file doesn't have one
+//   - Font is the embedded font, with one glyph per character, each glyph being FontHeight rows of 8 pixels,
+//     or nil if the file doesn't have one
+//   - Data is the (decompressed) image data, as character & attribute byte pairs (see DecodeBinaryText)
+type XBin struct {
+	Width      int
+	Height     int
+	FontHeight int
+	Flags      byte
+	Palette    *Palette
+	Font       [][]byte
+	Data       []byte
+}
+
+// ParseXBin parses XBin data (without a SAUCE record): the header, the optional palette & font,
+// and the compressed or uncompressed image data
+func ParseXBin(data []byte) (*XBin, error) {
+	if len(data) < 11 || !bytes.HasPrefix(data, []byte(XBinID)) {
+		return nil, fmt.Errorf("no valid XBin header found")
+	}
+	x := &XBin{
+		Width:      int(binary.LittleEndian.Uint16(data[5:7])),
+		Height:     int(binary.LittleEndian.Uint16(data[7:9])),
+		FontHeight: int(data[9]),
+		Flags:      data[10],
+	}
+	if x.FontHeight == 0 {
+		x.FontHeight = 16
+	}
+	data = data[11:]
+
+	if x.Flags&XBinFlagPalette != 0 {
+		if len(data) < 48 {
+			return nil, fmt.Errorf("XBin data too short to contain a palette")
+		}
+		// each channel is a 6 bit VGA DAC value (0-63), scaled up to 8 bits
+		x.Palette = &Palette{}
+		for i := range x.Palette {
+			c := data[i*3 : i*3+3]
+			x.Palette[i] = RGB{c[0]<<2 | c[0]>>4, c[1]<<2 | c[1]>>4, c[2]<<2 | c[2]>>4}
+		}
+		data = data[48:]
+	}
+
+	if x.Flags&XBinFlagFont != 0 {
+		nChars := 256
+		if x.Flags&XBinFlag512Chars != 0 {
+			nChars = 512
+		}
+		size := nChars * x.FontHeight
+		if len(data) < size {
+			return nil, fmt.Errorf("XBin data too short to contain a %d character font", nChars)
+		}
+		x.Font = make([][]byte, nChars)
+		for i := range x.Font {
+			x.Font[i] = data[i*x.FontHeight : (i+1)*x.FontHeight]
+		}
+		data = data[size:]
+	}
+
+	size := x.Width * x.Height * 2
+	if x.Flags&XBinFlagCompress == 0 {
+		if len(data) < size {
+			return nil, fmt.Errorf("XBin image data too short, expected %d bytes but found %d", size, len(data))
+		}
+		x.Data = data[:size]
+		return x, nil
+	}
+	var err error
+	x.Data, err = decompressXBin(data, size)
+	return x, err
+}
+
+// decompressXBin decompresses XBin image data into size bytes of character & attribute pairs.
+// Each run starts with a byte where the top 2 bits are the compression type, and the other 6 bits are the length - 1:
+//   - 0: no compression, followed by length character & attribute pairs
+//   - 1: character compression, followed by a character, and length attributes to draw it with
+//   - 2: attribute compression, followed by an attribute, and length characters to draw with it
+//   - 3: character & attribute compression, followed by a single pair that is repeated length times
+func decompressXBin(data []byte, size int) ([]byte, error) {
+	// the size comes from the header, so the output grows with the runs rather than trusting it up front
+	out := make([]byte, 0, min(size, len(data)*2))
+	for i := 0; len(out) < size; {
+		if i >= len(data) {
+			return nil, fmt.Errorf("XBin image data too short, expected %d bytes but found %d", size, len(out))
+		}
+		kind, length := data[i]>>6, int(data[i]&0x3f)+1
+		i++
+
+		// the number of bytes that the run reads after its first byte
+		need := 2
+		switch kind {
+		case 0:
+			need = length * 2
+		case 1, 2:
+			need = 1 + length
+		}
+		if i+need > len(data) {
+			return nil, fmt.Errorf("XBin image data too short, expected %d bytes but found %d", size, len(out))
+		}
+		for n := range length {
+			switch kind {
+			case 0:
+				out = append(out, data[i+n*2], data[i+n*2+1])
+			case 1:
+				out = append(out, data[i], data[i+1+n])
+			case 2:
+				out = append(out, data[i+1+n], data[i])
+			case 3:
+				out = append(out, data[i], data[i+1])
+			}
+		}
+		i += need
+	}
+	return out[:size], nil
+}
+
+// HasNonBlinkMode returns true if the iCE colour flag is set
+func (x *XBin) HasNonBlinkMode() bool {
+	return x.Flags&XBinFlagNonBlink != 0
+}
+
+// Tokens converts the XBin image to tokenized ANSI lines.
+// The colours are truecolor from the embedded palette, or the 16 ANSI colours if there isn't one (see dosColour).
+// Bit 7 of the attribute is a high intensity background with iCE colour, otherwise it is kept as blink.
+// With a 512 character font, bit 3 of the attribute selects the second 256 characters (leaving 8 foreground colours),
+// which are drawn as the glyphs of the first 256, as runes can only be mapped to CP437.
+func (x *XBin) Tokens() [][]ANSILineToken {
+	code := func(index byte, background bool) string {
+		if x.Palette != nil {
+			return "\x1b[" + trueColourCode(x.Palette[index], background) + "m"
+		}
+		return "\x1b[" + ansiCode(int(dosColour(index)), background) + "m"
+	}
+
+	grid := make([][]Cell, x.Height)
+	for y := range grid {
+		grid[y] = make([]Cell, x.Width)
+		for i := range grid[y] {
+			char, attr := x.Data[(y*x.Width+i)*2], x.Data[(y*x.Width+i)*2+1]
+			fg, bg, blink := attr&0x0f, attr>>4, false
+			if x.Flags&XBinFlag512Chars != 0 {
+				fg &= 0x07
+			}
+			if !x.HasNonBlinkMode() {
+				bg, blink = bg&0x07, bg&0x08 != 0
+			}
+			cell := Cell{FG: code(fg, false), BG: code(bg, true), R: CP437Rune(char)}
+			if blink {
+				cell.FG = "\x1b[5m" + cell.FG
+			}
+			grid[y][i] = cell
+		}
+	}
+	return CellsToTokens(grid)
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// Font is a CP437 bitmap font used by the raster renderers
+//   - Width is the width of a character cell, either 8 or 9 pixels.
+//     9 pixel fonts repeat the 8th column of the line-drawing glyphs (0xC0-0xDF) like VGA hardware,
+//     and leave the 9th column blank for all other glyphs
+//   - Height is the number of rows in each glyph, and
+//   - Glyphs has one glyph per CP437 byte value, each glyph being Height rows of 8 pixels
+type Font struct {
+	Name   string
+	Width  int
+	Height int
+	Glyphs [][]byte
+}
+
+// VGAFont returns the built-in IBM VGA 8x16 font, with a cell width of 8 or 9 pixels.
+func VGAFont(width int) *Font {
+	glyphs := make([][]byte, len(vgaGlyphs))
+	for i := range vgaGlyphs {
+		glyphs[i] = vgaGlyphs[i][:]
+	}
+	return &Font{Name: "IBM VGA", Width: width, Height: glyphHeight, Glyphs: glyphs}
+}
+
+// FontForSAUCE returns the font to render with from the SAUCE font name and letter spacing.
+// Only the IBM VGA 8x16 glyphs are built in, so the other IBM PC fonts (e.g. "IBM EGA" or "IBM VGA50")
+// are drawn with them resized to the height of the font.
+// Any other font (e.g. the Amiga fonts) falls back to IBM VGA, returning the font with an error.
+func FontForSAUCE(sauce *convert.SAUCE) (*Font, error) {
+	g := cellGeometry(sauce)
+	font := VGAFont(g.width)
+	if sauce == nil {
+		return font, nil
+	}
+	name := sauce.GetFontName()
+	if _, ok := lookupSAUCEFont(name); !ok {
+		return font, fmt.Errorf("font %q is not built in, using IBM VGA", name)
+	}
+	if g.height != font.Height {
+		font = font.resize(g.height)
+		font.Name = name
+	}
+	return font, nil
+}
+
+// resize returns a copy of the font with each glyph scaled to the given height, repeating or dropping rows
+func (f *Font) resize(height int) *Font {
+	glyphs := make([][]byte, len(f.Glyphs))
+	for i, glyph := range f.Glyphs {
+		glyphs[i] = make([]byte, height)
+		for y := range height {
+			glyphs[i][y] = glyph[y*f.Height/height]
+		}
+	}
+	return &Font{Name: f.Name, Width: f.Width, Height: height, Glyphs: glyphs}
+}
+
+// XBinFont returns the font embedded in an XBin file, or nil if it doesn't have one.
+// XBin fonts are always 8 pixels wide.
+func XBinFont(xbin *convert.XBin) *Font {
+	if xbin == nil || xbin.Font == nil {
+		return nil
+	}
+	return &Font{Name: "XBin", Width: 8, Height: xbin.FontHeight, Glyphs: xbin.Font}
+}
+
+// Pixel returns true if the pixel at (x, y) of the glyph for CP437 byte b is set
+func (f *Font) Pixel(b byte, x, y int) bool {
+	if int(b) >= len(f.Glyphs) || y >= len(f.Glyphs[b]) {
+		return false
+	}
+	if x >= 8 {
+		if x > 8 || b < 0xC0 || b > 0xDF {
+			return false
+		}
+		x = 7
+	}
+	return f.Glyphs[b][y]&(0x80>>x) != 0
+}
+
+// CP437Byte returns the CP437 byte value used to draw a rune, and false if the rune
+// isn't in code page 437 (in which case '?' is returned)
+func CP437Byte(r rune) (byte, bool) {
+	return convert.CP437Byte(r)
+}
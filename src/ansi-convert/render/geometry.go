@@ -0,0 +1,68 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// glyphHeight is the height of a character cell in the VGA text mode fonts
+const glyphHeight = 16
+
+// sauceFont is the glyph height of an IBM PC font, and the height of the text mode screen that it fills
+type sauceFont struct {
+	height       int
+	screenHeight int
+}
+
+// sauceFonts are the SAUCE font names that can be drawn with the built-in glyphs (see FontForSAUCE)
+var sauceFonts = map[string]sauceFont{
+	"IBM VGA":    {height: 16, screenHeight: 400},
+	"IBM VGA50":  {height: 8, screenHeight: 400},
+	"IBM VGA25G": {height: 19, screenHeight: 480},
+	"IBM EGA":    {height: 14, screenHeight: 350},
+	"IBM EGA43":  {height: 8, screenHeight: 350},
+}
+
+// lookupSAUCEFont returns the size of a SAUCE font name (with an optional code page 437 suffix),
+// and false if it isn't one of the sauceFonts. An empty name is the IBM VGA font.
+func lookupSAUCEFont(name string) (sauceFont, bool) {
+	if name == "" {
+		return sauceFonts["IBM VGA"], true
+	}
+	f, ok := sauceFonts[strings.TrimSuffix(name, " 437")]
+	return f, ok
+}
+
+// geometry is the size of a character cell in pixels
+//   - width is 8 or 9 pixels depending on the SAUCE letter spacing,
+//   - height is the unscaled glyph height, and
+//   - scaleY is the vertical stretch needed to reproduce the original aspect ratio (1 for square pixels)
+type geometry struct {
+	width  int
+	height int
+	scaleY float64
+}
+
+// cellGeometry returns the character cell geometry from the SAUCE font name and flags.
+// The legacy (unset) letter spacing uses an 8 pixel font, like ansilove, and fonts that aren't
+// one of the sauceFonts use the IBM VGA height.
+// When the aspect ratio is stretched, the screen of the font (e.g. 640x400 or 720x400 for VGA) is scaled to fill a 4:3 display.
+func cellGeometry(sauce *convert.SAUCE) geometry {
+	g := geometry{width: 8, height: glyphHeight, scaleY: 1}
+	if sauce == nil {
+		return g
+	}
+	font, ok := lookupSAUCEFont(sauce.GetFontName())
+	if !ok {
+		font = sauceFonts["IBM VGA"]
+	}
+	g.height = font.height
+	if sauce.GetLetterSpacing() == convert.LetterSpacing9Pixel {
+		g.width = 9
+	}
+	if sauce.GetAspectRatio() == convert.AspectRatioLegacy1 {
+		g.scaleY = (3.0 / 4.0) * (float64(g.width*80) / float64(font.screenHeight))
+	}
+	return g
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"time"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// GIF renders frames of tokenised lines (e.g. the frames of an ANSImation, see screen.Frames) to an animated GIF
+// that loops forever, showing each frame for the given delay (at least 10ms, the smallest delay a GIF can store).
+// Each frame is drawn as for Image, at the size of the largest frame.
+// When iCE colour is off, a single frame with blinking text is drawn twice, with the blinking text hidden
+// on the second frame.
+func GIF(frames [][][]convert.ANSILineToken, sauce *convert.SAUCE, font *Font, delay time.Duration) ([]byte, error) {
+	if font == nil {
+		font, _ = FontForSAUCE(sauce)
+	}
+	iceColour := sauce != nil && sauce.HasNonBlinkMode()
+
+	grids := make([][][]convert.Cell, len(frames))
+	for i, frame := range frames {
+		grids[i] = convert.TokensToCells(frame)
+	}
+	if len(grids) == 1 && !iceColour && hasBlink(grids[0]) {
+		grids = append(grids, grids[0])
+	}
+
+	images := make([]*image.RGBA, len(grids))
+	bounds := image.Rectangle{}
+	for i, grid := range grids {
+		images[i] = stretch(drawCells(grid, font, iceColour, i < len(frames)), cellGeometry(sauce).scaleY)
+		bounds = bounds.Union(images[i].Bounds())
+	}
+
+	anim := &gif.GIF{}
+	for _, img := range images {
+		anim.Image = append(anim.Image, paletted(img, bounds))
+		anim.Delay = append(anim.Delay, max(int(delay/(10*time.Millisecond)), 1))
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hasBlink returns true if any cell in the grid has blinking text (without iCE colour)
+func hasBlink(grid [][]convert.Cell) bool {
+	for _, row := range grid {
+		for _, cell := range row {
+			if _, _, blink := effectiveColours(resolveStyle(cell.FG, cell.BG), false); blink {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paletted converts an image to a paletted image with the given bounds, where the area outside of the image
+// is the default background. The palette has the colours of the image, or if there are more than 256,
+// the image is dithered to the Plan 9 palette.
+func paletted(img *image.RGBA, bounds image.Rectangle) *image.Paletted {
+	background := rgba(convert.VGAPalette[defaultBG])
+	indexes := map[color.RGBA]uint8{background: 0}
+	colours := color.Palette{background}
+	for i := 0; i < len(img.Pix) && len(colours) <= 256; i += 4 {
+		c := color.RGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+		if _, ok := indexes[c]; !ok {
+			indexes[c] = uint8(len(colours))
+			colours = append(colours, c)
+		}
+	}
+
+	if len(colours) > 256 {
+		out := image.NewPaletted(bounds, palette.Plan9)
+		draw.Draw(out, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+		draw.FloydSteinberg.Draw(out, img.Bounds(), img, image.Point{})
+		return out
+	}
+	// every pixel starts as index 0, the background
+	out := image.NewPaletted(bounds, colours)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			out.SetColorIndex(x, y, indexes[img.RGBAAt(x, y)])
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// HTML renders tokenised lines as a standalone HTML document.
+// The art is drawn in a <pre> block, with a <span> for each run of colours, and a stylesheet
+// containing a class for each colour used (e.g. "fg-9", "bg-4" or "fg-ff8700").
+// When the SAUCE record has iCE colour set (non-blink mode), blink renders as a bright background.
+func HTML(lines [][]convert.ANSILineToken, sauce *convert.SAUCE) string {
+	iceColour := sauce != nil && sauce.HasNonBlinkMode()
+	title := "ANSI art"
+	if sauce != nil && strings.TrimSpace(sauce.Title) != "" {
+		title = strings.TrimSpace(sauce.Title)
+	}
+
+	classes := make(map[string]string)
+	var body strings.Builder
+
+	for i, tokens := range lines {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		currClass, text := "", ""
+		flush := func() {
+			if text == "" {
+				return
+			}
+			if currClass == "" {
+				body.WriteString(html.EscapeString(text))
+			} else {
+				fmt.Fprintf(&body, `<span class="%s">%s</span>`, currClass, html.EscapeString(text))
+			}
+			text = ""
+		}
+		for _, token := range tokens {
+			if token.T == "" {
+				continue
+			}
+			class := htmlClasses(resolveStyle(token.FG, token.BG), iceColour, classes)
+			if class != currClass {
+				flush()
+				currClass = class
+			}
+			text += token.T
+		}
+		flush()
+	}
+
+	var doc strings.Builder
+	doc.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&doc, "<title>%s</title>\n", html.EscapeString(title))
+	doc.WriteString("<style>\n")
+	fmt.Fprintf(&doc,
+		"pre.ansi { color: #%s; background-color: #%s; font-family: monospace; line-height: 1; }\n",
+		convert.VGAPalette[defaultFG].Hex(), convert.VGAPalette[defaultBG].Hex(),
+	)
+	names := make([]string, 0, len(classes))
+	for name := range classes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&doc, ".%s { %s }\n", name, classes[name])
+	}
+	if _, ok := classes["blink"]; ok {
+		doc.WriteString("@keyframes blink { 50% { color: transparent; } }\n")
+	}
+	doc.WriteString("</style>\n</head>\n<body>\n<pre class=\"ansi\">")
+	doc.WriteString(body.String())
+	doc.WriteString("</pre>\n</body>\n</html>\n")
+	return doc.String()
+}
+
+// htmlClasses returns the space-separated CSS classes for a style,
+// adding the rule for each class to the stylesheet map
+func htmlClasses(s convert.Style, iceColour bool, stylesheet map[string]string) string {
+	fg, bg, blink := effectiveColours(s, iceColour)
+	classes := make([]string, 0, 3)
+
+	if fg.Kind != convert.ColourDefault {
+		name := "fg-" + colourClassName(fg)
+		stylesheet[name] = fmt.Sprintf("color: #%s;", fg.ToRGB(defaultFG).Hex())
+		classes = append(classes, name)
+	}
+	if bg.Kind != convert.ColourDefault {
+		name := "bg-" + colourClassName(bg)
+		stylesheet[name] = fmt.Sprintf("background-color: #%s;", bg.ToRGB(defaultBG).Hex())
+		classes = append(classes, name)
+	}
+	if blink {
+		stylesheet["blink"] = "animation: blink 1s step-end infinite;"
+		classes = append(classes, "blink")
+	}
+	return strings.Join(classes, " ")
+}
+
+// colourClassName returns the palette index (e.g. "9") or hex value (e.g. "ff8700") of a colour
+func colourClassName(c convert.Colour) string {
+	if c.Kind == convert.ColourIndexed {
+		return fmt.Sprintf("%d", c.Index)
+	}
+	return c.RGB.Hex()
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// Image rasterises tokenised lines with a bitmap font, like ansilove or PabloDraw.
+// If font is nil, the font is chosen from the SAUCE record, falling back to IBM VGA (see FontForSAUCE).
+// iCE colour and the aspect ratio are taken from the SAUCE record, and blinking text is drawn in its visible state.
+func Image(lines [][]convert.ANSILineToken, sauce *convert.SAUCE, font *Font) *image.RGBA {
+	if font == nil {
+		font, _ = FontForSAUCE(sauce)
+	}
+	iceColour := sauce != nil && sauce.HasNonBlinkMode()
+	img := drawCells(convert.TokensToCells(lines), font, iceColour, true)
+	return stretch(img, cellGeometry(sauce).scaleY)
+}
+
+// PNG renders tokenised lines to PNG image data (see Image)
+func PNG(lines [][]convert.ANSILineToken, sauce *convert.SAUCE, font *Font) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Image(lines, sauce, font)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawCells draws a grid of cells, one font cell per grid cell.
+// When blinkVisible is false, the foreground of blinking cells is hidden.
+func drawCells(grid [][]convert.Cell, font *Font, iceColour, blinkVisible bool) *image.RGBA {
+	cols := 0
+	for _, row := range grid {
+		cols = max(cols, len(row))
+	}
+	img := image.NewRGBA(image.Rect(0, 0, cols*font.Width, len(grid)*font.Height))
+	background := rgba(convert.VGAPalette[defaultBG])
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = background.R, background.G, background.B, background.A
+	}
+
+	for y, row := range grid {
+		for x, cell := range row {
+			fg, bg, blink := effectiveColours(resolveStyle(cell.FG, cell.BG), iceColour)
+			fgColour, bgColour := rgba(fg.ToRGB(defaultFG)), rgba(bg.ToRGB(defaultBG))
+			b, _ := CP437Byte(cell.R)
+			if cell.R == 0 || (blink && !blinkVisible) {
+				b = ' '
+			}
+			for py := 0; py < font.Height; py++ {
+				for px := 0; px < font.Width; px++ {
+					c := bgColour
+					if font.Pixel(b, px, py) {
+						c = fgColour
+					}
+					img.SetRGBA(x*font.Width+px, y*font.Height+py, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// stretch scales an image vertically by the given factor, using the nearest row
+func stretch(img *image.RGBA, scaleY float64) *image.RGBA {
+	if scaleY == 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	height := int(math.Round(float64(bounds.Dy()) * scaleY))
+	stretched := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), height))
+	for y := 0; y < height; y++ {
+		srcY := min(int(float64(y)/scaleY), bounds.Dy()-1)
+		copy(stretched.Pix[y*stretched.Stride:(y+1)*stretched.Stride], img.Pix[srcY*img.Stride:(srcY+1)*img.Stride])
+	}
+	return stretched
+}
+
+// rgba converts a palette colour to an opaque image colour
+func rgba(c convert.RGB) color.RGBA {
+	return color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff}
+}
@@ -0,0 +1,56 @@
+package render
+
+import "github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+
+// Default colours (indexes into the VGA palette) used when a token has no colour set
+const (
+	defaultFG = 7
+	defaultBG = 0
+)
+
+// resolveStyle parses the escape codes in a token's FG and BG strings into a style
+func resolveStyle(fg, bg string) convert.Style {
+	return convert.ParseStyle(fg + bg)
+}
+
+// effectiveColours applies the classic DOS rendering rules to a style:
+// bold makes the first 8 foreground colours bright, and (when iCE colour is enabled)
+// blink makes the first 8 background colours bright instead of blinking.
+// Reverse swaps the foreground & background, and conceal hides the foreground.
+// Returns the colours to draw, and whether the foreground should blink.
+func effectiveColours(s convert.Style, iceColour bool) (convert.Colour, convert.Colour, bool) {
+	fg, bg, blink := s.FG, s.BG, s.Blink
+	if s.Bold {
+		if fg.Kind == convert.ColourDefault {
+			fg = convert.IndexedColour(defaultFG)
+		}
+		if fg.Kind == convert.ColourIndexed && fg.Index < 8 {
+			fg.Index += 8
+		}
+	}
+	if s.Blink && iceColour {
+		if bg.Kind == convert.ColourDefault {
+			bg = convert.IndexedColour(defaultBG)
+		}
+		if bg.Kind == convert.ColourIndexed && bg.Index < 8 {
+			bg.Index += 8
+		}
+		blink = false
+	}
+	if s.Reverse {
+		if fg.Kind == convert.ColourDefault {
+			fg = convert.IndexedColour(defaultFG)
+		}
+		if bg.Kind == convert.ColourDefault {
+			bg = convert.IndexedColour(defaultBG)
+		}
+		fg, bg = bg, fg
+	}
+	if s.Conceal {
+		fg = bg
+		if fg.Kind == convert.ColourDefault {
+			fg = convert.IndexedColour(defaultBG)
+		}
+	}
+	return fg, bg, blink
+}
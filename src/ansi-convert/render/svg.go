@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// SVG renders tokenised lines as an SVG image.
+// Each cell is drawn as a background rect plus a text glyph, with the cell size following the SAUCE
+// letter spacing (8 or 9 pixels) and aspect ratio. Runs of cells with the same background share a rect,
+// and the default background is drawn once behind the whole image.
+func SVG(lines [][]convert.ANSILineToken, sauce *convert.SAUCE) string {
+	iceColour := sauce != nil && sauce.HasNonBlinkMode()
+	g := cellGeometry(sauce)
+	grid := convert.TokensToCells(lines)
+
+	cols := 0
+	for _, row := range grid {
+		cols = max(cols, len(row))
+	}
+	width, height := cols*g.width, len(grid)*g.height
+
+	var rects, glyphs strings.Builder
+	hasBlink := false
+
+	for y, row := range grid {
+		top := y * g.height
+		for x := 0; x < len(row); {
+			_, bg, _ := effectiveColours(resolveStyle(row[x].FG, row[x].BG), iceColour)
+
+			// extend the background rect over following cells with the same background
+			end := x + 1
+			for end < len(row) {
+				_, nextBG, _ := effectiveColours(resolveStyle(row[end].FG, row[end].BG), iceColour)
+				if nextBG != bg {
+					break
+				}
+				end++
+			}
+			if bg.Kind != convert.ColourDefault {
+				fmt.Fprintf(&rects,
+					"<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"#%s\"/>\n",
+					x*g.width, top, (end-x)*g.width, g.height, bg.ToRGB(defaultBG).Hex(),
+				)
+			}
+
+			for ; x < end; x++ {
+				cell := row[x]
+				if cell.R == 0 || cell.R == ' ' {
+					continue
+				}
+				fg, _, blink := effectiveColours(resolveStyle(cell.FG, cell.BG), iceColour)
+				cellWidth := g.width
+				if x+1 < len(row) && row[x+1].R == 0 {
+					cellWidth *= 2
+				}
+				class := ""
+				if blink {
+					class, hasBlink = ` class="blink"`, true
+				}
+				fmt.Fprintf(&glyphs,
+					"<text x=\"%g\" y=\"%d\" fill=\"#%s\"%s>%s</text>\n",
+					float64(x*g.width)+float64(cellWidth)/2, top+g.height*3/4, fg.ToRGB(defaultFG).Hex(), class,
+					html.EscapeString(string(cell.R)),
+				)
+			}
+		}
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%g\" viewBox=\"0 0 %d %d\" preserveAspectRatio=\"none\">\n",
+		width, math.Round(float64(height)*g.scaleY*100)/100, width, height,
+	)
+	fmt.Fprintf(&svg,
+		"<style>\ntext { font-family: monospace; font-size: %dpx; text-anchor: middle; white-space: pre; }\n",
+		g.height,
+	)
+	if hasBlink {
+		svg.WriteString(".blink { animation: blink 1s step-end infinite; }\n@keyframes blink { 50% { fill-opacity: 0; } }\n")
+	}
+	svg.WriteString("</style>\n")
+	fmt.Fprintf(&svg, "<rect width=\"100%%\" height=\"100%%\" fill=\"#%s\"/>\n", convert.VGAPalette[defaultBG].Hex())
+	svg.WriteString(rects.String())
+	svg.WriteString(glyphs.String())
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
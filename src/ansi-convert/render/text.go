@@ -0,0 +1,27 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// Text renders tokenised lines as plain text, keeping only the glyphs of each line.
+// Lines keep the width of the coloured output (including the spaces drawn for cursor forward codes),
+// unless trimRight is set, in which case trailing spaces are removed.
+func Text(lines [][]convert.ANSILineToken, trimRight bool) string {
+	var builder strings.Builder
+	for _, tokens := range lines {
+		var line strings.Builder
+		for _, token := range tokens {
+			line.WriteString(token.T)
+		}
+		if trimRight {
+			builder.WriteString(strings.TrimRight(line.String(), " "))
+		} else {
+			builder.WriteString(line.String())
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package screen
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+)
+
+// frameBoundary matches the sequences that start a new frame of an ANSImation:
+// cursor home (e.g. "\x1b[H", "\x1b[1;1H" or "\x1b[f") and clear screen ("\x1b[2J").
+// The parameters must all be 1 or missing, so that e.g. "\x1b[11H" (row 11) isn't a boundary.
+var frameBoundary = regexp.MustCompile(`\x1b\[(?:1?(?:;1?)?)[Hf]|\x1b\[2J`)
+
+// escapeSequence matches a CSI escape sequence, e.g. "\x1b[1;31m"
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]`)
+
+// SplitFrames splits an ANSImation into the escape codes & text of each frame, where a frame starts at
+// a cursor home or clear screen sequence. Boundaries that come before anything has been drawn
+// (e.g. a clear screen followed by a cursor home) start the same frame, and nothing after an EOF marker is kept.
+func SplitFrames(input string) []string {
+	if i := strings.IndexRune(input, '\x1a'); i >= 0 {
+		input = input[:i]
+	}
+	frames := make([]string, 0)
+	start := 0
+	for _, match := range frameBoundary.FindAllStringIndex(input, -1) {
+		if hasVisibleText(input[start:match[0]]) {
+			frames = append(frames, input[start:match[0]])
+			start = match[0]
+		}
+	}
+	if hasVisibleText(input[start:]) || len(frames) == 0 {
+		frames = append(frames, input[start:])
+	}
+	return frames
+}
+
+// hasVisibleText returns true if the string draws anything, ignoring escape sequences & line endings
+func hasVisibleText(s string) bool {
+	return strings.Trim(escapeSequence.ReplaceAllString(s, ""), "\r\n") != ""
+}
+
+// Frames replays each frame of an ANSImation (see SplitFrames) into the same Screen, returning the tokenised
+// lines of the screen after each frame, as frames often only redraw the parts of the screen that change.
+func Frames(input string, width int) [][][]convert.ANSILineToken {
+	s := New(width)
+	frames := make([][][]convert.ANSILineToken, 0)
+	for _, frame := range SplitFrames(input) {
+		s.WriteString(frame)
+		frames = append(frames, s.Lines())
+	}
+	return frames
+}
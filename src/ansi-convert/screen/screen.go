@@ -0,0 +1,266 @@
+package screen
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/log"
+)
+
+const tabWidth = 8
+
+// Screen is a virtual terminal that ANSI escape sequences can be replayed into.
+// It keeps a grid of cells, a cursor position and the current colour state,
+// and supports the cursor-addressing sequences used by scene art:
+//   - SGR colours (m), including the custom truecolor format (t)
+//   - cursor up/down/forward/back (A, B, C, D)
+//   - absolute positioning (H, f)
+//   - save/restore cursor (s, u)
+//   - clear screen and clear line (J, K)
+//
+// Width is the number of columns before the cursor wraps to the next row (0 disables wrapping).
+type Screen struct {
+	Width  int
+	cells  [][]convert.Cell
+	x, y   int
+	savedX int
+	savedY int
+	style  convert.Style
+}
+
+// New creates an empty Screen that wraps at the given number of columns.
+func New(width int) *Screen {
+	return &Screen{Width: width, cells: make([][]convert.Cell, 0)}
+}
+
+// Render replays a string containing ANSI escape codes into a new Screen,
+// returning the tokenised lines of what would be drawn on a terminal.
+func Render(input string, width int) [][]convert.ANSILineToken {
+	s := New(width)
+	s.WriteString(input)
+	return s.Lines()
+}
+
+// Lines returns the tokenised contents of the screen, one slice of tokens per row.
+func (s *Screen) Lines() [][]convert.ANSILineToken {
+	return convert.CellsToTokens(s.cells)
+}
+
+// WriteString replays a string containing ANSI escape codes into the screen.
+func (s *Screen) WriteString(input string) {
+	isEscape, sequence := false, ""
+
+	for _, ch := range input {
+		if isEscape {
+			sequence += string(ch)
+			if len(sequence) == 2 && ch != '[' {
+				// not a CSI sequence, ignore it
+				isEscape, sequence = false, ""
+				continue
+			}
+			if len(sequence) > 2 && ch >= 0x40 && ch <= 0x7e {
+				s.handleSequence(sequence)
+				isEscape, sequence = false, ""
+			}
+			continue
+		}
+		switch ch {
+		case '\033':
+			isEscape, sequence = true, string(ch)
+		case '\n':
+			s.x, s.y = 0, s.y+1
+		case '\r':
+			s.x = 0
+		case '\t':
+			s.x = (s.x/tabWidth + 1) * tabWidth
+		case '\x1a':
+			// EOF marker, nothing after this is drawn
+			return
+		case '\x1f', '\x06', '\x07':
+			// replace with a space: 0x1F (unit separator), 0x06 (acknowledge), 0x07 (bell)
+			s.Put(' ')
+		default:
+			s.Put(ch)
+		}
+	}
+}
+
+// Put draws a rune at the cursor with the current colours, and advances the cursor.
+func (s *Screen) Put(r rune) {
+	w := 1
+	if r >= 128 {
+		w = runewidth.RuneWidth(r)
+	}
+	if w == 0 {
+		return
+	}
+	if s.Width > 0 && s.x+w > s.Width {
+		s.x, s.y = 0, s.y+1
+	}
+	s.set(s.x, s.y, convert.Cell{FG: s.currentFG(), BG: s.style.BG.Code(true), R: r})
+	if w == 2 {
+		s.set(s.x+1, s.y, convert.Cell{FG: s.currentFG(), BG: s.style.BG.Code(true), R: 0})
+	}
+	s.x += w
+	if s.Width > 0 && s.x >= s.Width {
+		s.x, s.y = 0, s.y+1
+	}
+}
+
+// MoveTo moves the cursor to an absolute (zero-based) column and row.
+func (s *Screen) MoveTo(x, y int) {
+	s.x, s.y = max(x, 0), max(y, 0)
+	if s.Width > 0 && s.x >= s.Width {
+		s.x = s.Width - 1
+	}
+}
+
+// currentFG returns the foreground code for newly drawn cells, including any bold & blink modifiers
+func (s *Screen) currentFG() string {
+	fg := s.style.FG.Code(false)
+	if s.style.Blink {
+		fg = "\x1b[5m" + fg
+	}
+	if s.style.Bold {
+		fg = "\x1b[1m" + fg
+	}
+	return fg
+}
+
+// set writes a cell at the given position, growing the grid with blank cells as needed
+func (s *Screen) set(x, y int, cell convert.Cell) {
+	for len(s.cells) <= y {
+		s.cells = append(s.cells, make([]convert.Cell, 0))
+	}
+	for len(s.cells[y]) <= x {
+		s.cells[y] = append(s.cells[y], convert.Cell{R: ' '})
+	}
+	s.cells[y][x] = cell
+}
+
+// clear blanks the cells on row y from column `from` up to (but not including) column `to`.
+// A negative `to` clears to the end of the row.
+func (s *Screen) clear(y, from, to int) {
+	if y >= len(s.cells) {
+		return
+	}
+	if to < 0 || to > len(s.cells[y]) {
+		to = len(s.cells[y])
+	}
+	for x := from; x < to; x++ {
+		s.cells[y][x] = convert.Cell{R: ' '}
+	}
+}
+
+// handleSequence applies a single CSI escape sequence, e.g. "\x1b[10C"
+func (s *Screen) handleSequence(sequence string) {
+	final := sequence[len(sequence)-1]
+	params := parseParams(sequence[2 : len(sequence)-1])
+
+	// param returns the nth parameter, or the default if missing or zero
+	param := func(n, def int) int {
+		if n < len(params) && params[n] > 0 {
+			return params[n]
+		}
+		return def
+	}
+
+	switch final {
+	case 'm':
+		s.handleSGR(params)
+	case 't':
+		s.handleTrueColour(params)
+	case 'A':
+		s.y = max(s.y-param(0, 1), 0)
+	case 'B':
+		s.y += param(0, 1)
+	case 'C':
+		s.x += param(0, 1)
+		if s.Width > 0 && s.x >= s.Width {
+			s.x = s.Width - 1
+		}
+	case 'D':
+		s.x = max(s.x-param(0, 1), 0)
+	case 'H', 'f':
+		s.MoveTo(param(1, 1)-1, param(0, 1)-1)
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.x, s.y = s.savedX, s.savedY
+	case 'J':
+		switch param(0, 0) {
+		case 0:
+			s.clear(s.y, s.x, -1)
+			for y := s.y + 1; y < len(s.cells); y++ {
+				s.clear(y, 0, -1)
+			}
+		case 1:
+			for y := 0; y < s.y; y++ {
+				s.clear(y, 0, -1)
+			}
+			s.clear(s.y, 0, s.x+1)
+		case 2:
+			// like ANSI.SYS, clearing the screen also homes the cursor
+			s.cells = make([][]convert.Cell, 0)
+			s.x, s.y = 0, 0
+		}
+	case 'K':
+		switch param(0, 0) {
+		case 0:
+			s.clear(s.y, s.x, -1)
+		case 1:
+			s.clear(s.y, 0, s.x+1)
+		case 2:
+			s.clear(s.y, 0, -1)
+		}
+	default:
+		log.DebugFprintf("Ignoring unsupported escape sequence: %q\n", sequence)
+	}
+}
+
+// handleSGR applies a "select graphic rendition" sequence to the current colour state
+func (s *Screen) handleSGR(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+	s.style = s.style.ApplySGR(params)
+}
+
+// handleTrueColour applies the custom "\x1b[1;R;G;Bt" (foreground) and "\x1b[0;R;G;Bt" (background) codes
+func (s *Screen) handleTrueColour(params []int) {
+	if len(params) != 4 {
+		return
+	}
+	for _, v := range params[1:] {
+		if v > 255 {
+			return
+		}
+	}
+	c := convert.RGBColour(uint8(params[1]), uint8(params[2]), uint8(params[3]))
+	switch params[0] {
+	case 1:
+		s.style.FG = c
+	case 0:
+		if c.RGB == (convert.RGB{}) {
+			// Special case for black background - use default black background code
+			c = convert.IndexedColour(0)
+		}
+		s.style.BG = c
+	}
+}
+
+// parseParams splits the parameters of an escape sequence, e.g. "38;5;129" -> [38 5 129].
+// Missing parameters are returned as 0.
+func parseParams(s string) []int {
+	if s == "" {
+		return []int{}
+	}
+	parts := strings.Split(s, ";")
+	params := make([]int, len(parts))
+	for i, part := range parts {
+		params[i], _ = strconv.Atoi(strings.TrimLeft(part, "?="))
+	}
+	return params
+}
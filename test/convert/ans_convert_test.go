@@ -34,6 +34,22 @@ func TestConvertAnsStrings(t *testing.T) {
 			},
 			expectedString: "\x1b[42m          xxx\x1b[0m\n",
 		},
+		{
+			name:        "Blink turned off",
+			inputString: "\x1b[5;47;30mab\x1b[25mcd",
+			inputSAUCE: convert.SAUCE{
+				ID:       "SAUCE",
+				DataType: 1,
+				FileType: 1,
+				TInfo1: convert.TInfoField{
+					Name: "Character Width", Value: 4,
+				},
+				TInfo2: convert.TInfoField{
+					Name: "Number of lines", Value: 1,
+				},
+			},
+			expectedString: "\x1b[5m\x1b[30m\x1b[47mab\x1b[25m\x1b[30m\x1b[47mcd\x1b[0m\n",
+		},
 		{
 			name:        "Small single-line",
 			inputString: "\x1b[31m\x1b[40m123\x1b[36m\x1b[43mabc\x1b[0m",
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDecodeAvatar(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		width    int
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:  "Attributes, blink and repeat",
+			input: "a\x16\x01\x1fb\x19-\x03\x16\x02c\r\n\x0cd",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[36m", BG: "\x1b[40m", T: "d"}},
+			},
+		},
+		{
+			name:  "Attributes, blink and repeat before a clear screen",
+			input: "a\x16\x01\x1fb\x19-\x03\x16\x02c\r\nd",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "", BG: "", T: "a"},
+					{FG: "\x1b[97m", BG: "\x1b[44m", T: "b---"},
+					{FG: "\x1b[5m\x1b[97m", BG: "\x1b[44m", T: "c"},
+				},
+				{{FG: "\x1b[5m\x1b[97m", BG: "\x1b[44m", T: "d"}},
+			},
+		},
+		{
+			name:  "Cursor movement, clear to end of line and wrapping",
+			input: "abcdef\x16\x08\x01\x02X\x16\x06Y\x16\x03\x16\x05Z\x16\x08\x02\x01\x16\x07",
+			width: 4,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "aXcZ"}},
+				{{FG: "", BG: "", T: "    "}},
+			},
+		},
+		{
+			name:  "Areas, patterns, insert and delete",
+			input: "\x16\x0c\x20\x02\x03\x16\x0d\x0e*\x01\x02\x16\x08\x02\x02\x16\x19\x02ab\x02\x16\x08\x02\x01\x16\x0e\x16\x09!",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[93m", BG: "\x1b[40m", T: "**"}, {FG: "\x1b[30m", BG: "\x1b[42m", T: " "}},
+				{{FG: "\x1b[93m", BG: "\x1b[40m", T: "!abab"}},
+			},
+		},
+		{
+			name:  "Scrolling an area up",
+			input: "ab\r\ncd\r\nef\x16\x0a\x01\x01\x01\x03\x01",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "cb"}},
+				{{FG: "", BG: "", T: "ed"}},
+				{{FG: "", BG: "", T: " f"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.DecodeAvatar(tc.input, tc.width)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestConvertAnsAvatar(t *testing.T) {
+	sauce := convert.SAUCE{
+		DataType: convert.DataTypeCharacter,
+		FileType: convert.FileTypeCharacterAvatar,
+		TInfo1:   convert.TInfoField{Name: convert.TInfoNameCharacterWidth, Value: 4},
+	}
+	input := "\x0cab\x16\x01\x1e\x19!\x02"
+	expected := "\x1b[36m\x1b[40mab\x1b[93m\x1b[44m!!\x1b[0m\n"
+
+	result := convert.ConvertAns(input, sauce)
+
+	test.PrintSimpleTestResults(input, expected, result, t)
+	test.Assert(expected, result, t)
+}
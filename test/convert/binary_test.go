@@ -0,0 +1,115 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDecodeBinaryText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		width    int
+		expected string
+	}{
+		{
+			name:     "Attributes and control character glyphs",
+			input:    []byte("A\x07B\x1f\x01\x9c\x1b\x9c"),
+			width:    2,
+			expected: "\x1b[0;37;40mA\x1b[0;37;44;1mB\x1b[0m\n\x1b[0;31;44;1;5m☺←\x1b[0m\n",
+		},
+		{
+			name:     "A short last line",
+			input:    []byte("a\x70b\x70c\x07"),
+			width:    2,
+			expected: "\x1b[0;30;47mab\x1b[0m\n\x1b[0;37;40mc\x1b[0m\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.DecodeBinaryText(tc.input, tc.width)
+
+			test.PrintSimpleTestResults(string(tc.input), tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestConvertAnsBinaryText(t *testing.T) {
+	sauce := convert.SAUCE{DataType: convert.DataTypeBinaryText, FileType: 1, TFlags: convert.ANSiFlagNonBlinkMode}
+	// the CP437 decoded input, where the attribute 0x9c is '£'
+	input := "A\x07B\x1f\x01£"
+	expected := "\x1b[37m\x1b[40mA\x1b[97m\x1b[44mB\x1b[0m\n\x1b[91m\x1b[104m☺\x1b[0m \x1b[0m\n"
+
+	result := convert.ConvertAns(input, sauce)
+
+	test.PrintSimpleTestResults(input, expected, result, t)
+	test.Assert(expected, result, t)
+}
+
+func TestEncodeBinaryText(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         [][]convert.ANSILineToken
+		iceColour     bool
+		expected      []byte
+		expectedWidth int
+	}{
+		{
+			name: "High intensity backgrounds with iCE colour",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[91m", BG: "\x1b[104m", T: "☺a"}},
+			},
+			iceColour:     true,
+			expected:      []byte("\x01\x9ca\x9c"),
+			expectedWidth: 2,
+		},
+		{
+			name: "Blink without iCE colour, padded to an even width",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5m\x1b[31m", BG: "\x1b[104m", T: "x"}},
+			},
+			iceColour:     false,
+			expected:      []byte("x\x94 \x07"),
+			expectedWidth: 2,
+		},
+		{
+			name: "Truecolor, bold and runes outside of CP437",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;2;250;250;250m", BG: "", T: "█"}, {FG: "\x1b[1m\x1b[32m", BG: "", T: "中"}},
+				{{FG: "", BG: "", T: ""}},
+			},
+			iceColour:     true,
+			expected:      []byte("\xdb\x0f?\x0a \x0a \x07 \x07 \x07 \x07 \x07"),
+			expectedWidth: 4,
+		},
+		{
+			name:          "Empty input is at least 2 columns wide",
+			input:         [][]convert.ANSILineToken{},
+			iceColour:     false,
+			expected:      []byte{},
+			expectedWidth: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, width, err := convert.EncodeBinaryText(tc.input, tc.iceColour)
+
+			test.Assert(nil, err, t)
+			test.Assert(tc.expectedWidth, width, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+
+	_, _, err := convert.EncodeBinaryText([][]convert.ANSILineToken{{{FG: "", BG: "", T: string(make([]byte, 511))}}}, true)
+	test.Assert("invalid width 512, binary text can be at most 510 columns wide", err.Error(), t)
+}
+
+func TestBinaryTextWidth(t *testing.T) {
+	test.Assert(160, convert.BinaryTextWidth(convert.SAUCE{DataType: convert.DataTypeBinaryText}), t)
+	test.Assert(80, convert.BinaryTextWidth(convert.SAUCE{DataType: convert.DataTypeBinaryText, FileType: 40}), t)
+}
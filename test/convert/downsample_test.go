@@ -0,0 +1,126 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDownsampleColours(t *testing.T) {
+	input := [][]convert.ANSILineToken{
+		{
+			{FG: "\x1b[1m\x1b[38;2;255;0;0m", BG: "\x1b[48;2;0;0;170m", T: "a"},
+			{FG: "\x1b[38;5;196m", BG: "\x1b[48;5;4m", T: "b"},
+			{FG: "\x1b[93m", BG: "\x1b[100m", T: "c"},
+			{FG: "\x1b[0m", BG: "", T: "d"},
+		},
+	}
+	testCases := []struct {
+		name     string
+		depth    convert.ColourDepth
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:  "256 colours",
+			depth: convert.Depth256,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[38;5;196m", BG: "\x1b[48;5;19m", T: "a"},
+					{FG: "\x1b[38;5;196m", BG: "\x1b[48;5;4m", T: "b"},
+					{FG: "\x1b[93m", BG: "\x1b[100m", T: "c"},
+					{FG: "\x1b[0m", BG: "", T: "d"},
+				},
+			},
+		},
+		{
+			name:  "16 colours",
+			depth: convert.Depth16,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[91m", BG: "\x1b[44m", T: "a"},
+					{FG: "\x1b[91m", BG: "\x1b[44m", T: "b"},
+					{FG: "\x1b[93m", BG: "\x1b[100m", T: "c"},
+					{FG: "\x1b[0m", BG: "", T: "d"},
+				},
+			},
+		},
+		{
+			name:  "8 colours",
+			depth: convert.Depth8,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[31m", BG: "\x1b[44m", T: "a"},
+					{FG: "\x1b[31m", BG: "\x1b[44m", T: "b"},
+					{FG: "\x1b[33m", BG: "\x1b[40m", T: "c"},
+					{FG: "\x1b[0m", BG: "", T: "d"},
+				},
+			},
+		},
+		{
+			name:  "Monochrome",
+			depth: convert.DepthMono,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m", BG: "", T: "a"},
+					{FG: "", BG: "", T: "b"},
+					{FG: "", BG: "", T: "c"},
+					{FG: "\x1b[0m", BG: "", T: "d"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.DownsampleColours(input, tc.depth, convert.MetricCIEDE2000)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestNearestColour(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    convert.RGB
+		metric   convert.ColourMetric
+		expected int
+	}{
+		{"Exact match", convert.RGB{R: 0xaa, G: 0x55, B: 0x00}, convert.MetricEuclidean, 3},
+		{"Near white", convert.RGB{R: 240, G: 240, B: 250}, convert.MetricEuclidean, 15},
+		{"Near bright blue", convert.RGB{R: 80, G: 90, B: 240}, convert.MetricCIEDE2000, 12},
+		// navy is nearer to black in RGB space, but looks more like blue
+		{"Navy (euclidean)", convert.RGB{R: 0, G: 0, B: 80}, convert.MetricEuclidean, 0},
+		{"Navy (ciede2000)", convert.RGB{R: 0, G: 0, B: 80}, convert.MetricCIEDE2000, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.NearestColour(tc.input, convert.VGAPalette[:], tc.metric)
+
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestCIEDE2000(t *testing.T) {
+	// reference values from Sharma, Wu & Dalal (2005), "The CIEDE2000 Color-Difference Formula"
+	testCases := []struct {
+		c1, c2   convert.Lab
+		expected float64
+	}{
+		{convert.Lab{L: 50, A: 2.6772, B: -79.7751}, convert.Lab{L: 50, A: 0, B: -82.7485}, 2.0425},
+		{convert.Lab{L: 50, A: -1.3802, B: -84.2814}, convert.Lab{L: 50, A: 0, B: -82.7485}, 1.0000},
+		{convert.Lab{L: 50, A: 2.5, B: 0}, convert.Lab{L: 73, A: 25, B: -18}, 27.1492},
+		{convert.Lab{L: 60.2574, A: -34.0099, B: 36.2677}, convert.Lab{L: 60.4626, A: -34.1751, B: 39.4387}, 1.2644},
+		{convert.Lab{L: 2.0776, A: 0.0795, B: -1.1350}, convert.Lab{L: 0.9033, A: -0.0636, B: -0.5514}, 0.9082},
+	}
+
+	for _, tc := range testCases {
+		result := convert.CIEDE2000(tc.c1, tc.c2)
+		test.Assert(tc.expected, math.Round(result*10000)/10000, t)
+	}
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+// newImage returns an image with a colour for each pixel, where nil is transparent
+func newImage(pixels [][]color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, len(pixels[0]), len(pixels)))
+	for y, row := range pixels {
+		for x, c := range row {
+			if c != nil {
+				img.Set(x, y, c)
+			}
+		}
+	}
+	return img
+}
+
+func TestImageToTokens(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	grey := color.NRGBA{128, 128, 128, 255}
+	vgaRed := color.NRGBA{170, 0, 0, 255}
+	vgaBlue := color.NRGBA{0, 0, 170, 255}
+
+	testCases := []struct {
+		name     string
+		image    image.Image
+		opts     convert.ImageOptions
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name: "Truecolor half blocks",
+			image: newImage([][]color.Color{
+				{red, red},
+				{blue, blue},
+			}),
+			opts: convert.ImageOptions{},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;2;255;0;0m", BG: "\x1b[48;2;0;0;255m", T: "▀▀"}},
+			},
+		},
+		{
+			name: "Transparent pixels and an odd height",
+			image: newImage([][]color.Color{
+				{red, nil, red},
+				{blue, blue, red},
+				{nil, red, nil},
+			}),
+			opts: convert.ImageOptions{},
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[38;2;255;0;0m", BG: "\x1b[48;2;0;0;255m", T: "▀"},
+					{FG: "\x1b[38;2;0;0;255m", BG: "\x1b[49m", T: "▄"},
+					{FG: "\x1b[39m", BG: "\x1b[48;2;255;0;0m", T: " "},
+				},
+				{
+					{FG: "", BG: "", T: " "},
+					{FG: "\x1b[38;2;255;0;0m", BG: "", T: "▀"},
+					{FG: "\x1b[0m", BG: "", T: " "},
+				},
+			},
+		},
+		{
+			name: "16 colours",
+			image: newImage([][]color.Color{
+				{vgaRed, color.NRGBA{180, 10, 0, 255}},
+				{vgaBlue, vgaBlue},
+			}),
+			opts: convert.ImageOptions{Depth: convert.Depth16, Metric: convert.MetricEuclidean},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "▀▀"}},
+			},
+		},
+		{
+			name: "Resized to a width",
+			image: newImage([][]color.Color{
+				{red, blue, red, red},
+				{red, blue, nil, nil},
+			}),
+			opts: convert.ImageOptions{Width: 2},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;2;128;0;128m", BG: "", T: "▀"}, {FG: "\x1b[38;2;255;0;0m", BG: "", T: "▀"}},
+			},
+		},
+		{
+			name: "Resized to a width that would round the height to 0",
+			image: newImage([][]color.Color{
+				{red, red, red, blue, blue, blue},
+			}),
+			opts: convert.ImageOptions{Width: 2},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;2;255;0;0m", BG: "", T: "▀"}, {FG: "\x1b[38;2;0;0;255m", BG: "", T: "▀"}},
+			},
+		},
+		{
+			name: "Mono with dithering",
+			image: newImage([][]color.Color{
+				{grey, grey, grey, grey},
+				{grey, grey, grey, grey},
+			}),
+			opts: convert.ImageOptions{Depth: convert.DepthMono, Metric: convert.MetricEuclidean, Dither: true},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "▀▄▀▄"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.ImageToTokens(tc.image, tc.opts)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
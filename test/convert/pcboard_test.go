@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDecodePCBoard(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Colour codes",
+			input:    "@X1FHello @x0eworld\r\n@X4Cred",
+			expected: "\x1b[0;37;44;1mHello \x1b[0;33;40;1mworld\n\x1b[0;31;41;1mred",
+		},
+		{
+			name:     "Saving and restoring colours",
+			input:    "@X1Fa@X00@X07b@XFFc",
+			expected: "\x1b[0;37;44;1ma\x1b[0;37;40mb\x1b[0;37;44;1mc",
+		},
+		{
+			name:     "Clear screen, cursor position and ignored macros",
+			input:    "gone@X02@CLS@ab@POS:5@c@PAUSE@@MORE@ @USER@",
+			expected: "\x1b[0;32;40mab  c @USER@",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.DecodePCBoard(tc.input)
+
+			test.PrintSimpleTestResults(tc.input, tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestConvertAnsPCBoard(t *testing.T) {
+	sauce := convert.SAUCE{
+		DataType: convert.DataTypeCharacter,
+		FileType: convert.FileTypeCharacterPCBoard,
+		TInfo1:   convert.TInfoField{Name: convert.TInfoNameCharacterWidth, Value: 4},
+		TInfo2:   convert.TInfoField{Name: convert.TInfoNameNumberOfLines, Value: 1},
+	}
+	input := "@X1Fab@X9Ec\r\n"
+	expected := "\x1b[97m\x1b[44mab\x1b[5m\x1b[93m\x1b[44mc\x1b[0m \x1b[0m\n"
+
+	result := convert.ConvertAns(input, sauce)
+
+	test.PrintSimpleTestResults(input, expected, result, t)
+	test.Assert(expected, result, t)
+}
+
+func TestEncodePCBoard(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [][]convert.ANSILineToken
+		expected string
+	}{
+		{
+			name: "Colour changes across lines, with trailing spaces removed",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[97m", BG: "\x1b[44m", T: "ab"}, {FG: "\x1b[0m", BG: "", T: "c  "}},
+				{{FG: "", BG: "", T: "d"}, {FG: "", BG: "\x1b[41m", T: " "}},
+			},
+			expected: "@X1Fab@X07c\r\nd@X47 \r\n",
+		},
+		{
+			name: "Black on black, and runes outside of CP437",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[30m", BG: "\x1b[40m", T: "x"}, {FG: "\x1b[32m", BG: "", T: "░中!"}},
+			},
+			expected: "@X07 @X02\xb0? !\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.EncodePCBoard(tc.input, false)
+
+			test.Assert(tc.expected, string(result), t)
+		})
+	}
+}
+
+func TestHasPCBoardCodes(t *testing.T) {
+	test.Assert(true, convert.HasPCBoardCodes("text @X1f"), t)
+	test.Assert(false, convert.HasPCBoardCodes("user@example.com @CLS@"), t)
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestParseStyle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected convert.Style
+	}{
+		{
+			name:     "No codes is the default style",
+			input:    "",
+			expected: convert.Style{},
+		},
+		{
+			name:     "16 colours and attributes",
+			input:    "\x1b[1;31m\x1b[44m",
+			expected: convert.Style{FG: convert.IndexedColour(1), BG: convert.IndexedColour(4), Bold: true},
+		},
+		{
+			name:     "Bright colours",
+			input:    "\x1b[96;105m",
+			expected: convert.Style{FG: convert.IndexedColour(14), BG: convert.IndexedColour(13)},
+		},
+		{
+			name:     "256 colours and truecolor",
+			input:    "\x1b[38;5;208m\x1b[48;2;1;2;3m",
+			expected: convert.Style{FG: convert.IndexedColour(208), BG: convert.RGBColour(1, 2, 3)},
+		},
+		{
+			name:     "Extended colour parameters aren't read as attributes",
+			input:    "\x1b[38;5;1;4m",
+			expected: convert.Style{FG: convert.IndexedColour(1), Underline: true},
+		},
+		{
+			name:     "Extended colours outside of 0-255 are ignored",
+			input:    "\x1b[31;44m\x1b[38;5;256m\x1b[48;2;0;300;0m",
+			expected: convert.Style{FG: convert.IndexedColour(1), BG: convert.IndexedColour(4)},
+		},
+		{
+			name:     "Reset clears earlier codes",
+			input:    "\x1b[1;5;31m\x1b[0;44m",
+			expected: convert.Style{BG: convert.IndexedColour(4)},
+		},
+		{
+			name:     "Empty code is a reset",
+			input:    "\x1b[7;32m\x1b[m",
+			expected: convert.Style{},
+		},
+		{
+			name:     "Attributes are turned off individually",
+			input:    "\x1b[1;2;3;4;5;7;8;9m\x1b[22;23;25;27m\x1b[39;49m",
+			expected: convert.Style{Underline: true, Conceal: true, Strike: true},
+		},
+		{
+			name:     "Non-SGR sequences are ignored",
+			input:    "\x1b[2J\x1b[32m\x1b[10C",
+			expected: convert.Style{FG: convert.IndexedColour(2)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.ParseStyle(tc.input)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestStyleString(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          convert.Style
+		expectedParams string
+		expected       string
+	}{
+		{
+			name:           "Default style",
+			input:          convert.Style{},
+			expectedParams: "",
+			expected:       "\x1b[0m",
+		},
+		{
+			name:           "Attributes and 16 colours",
+			input:          convert.Style{FG: convert.IndexedColour(9), BG: convert.IndexedColour(4), Bold: true, Blink: true},
+			expectedParams: "1;5;91;44",
+			expected:       "\x1b[0;1;5;91;44m",
+		},
+		{
+			name:           "256 colours and truecolor",
+			input:          convert.Style{FG: convert.IndexedColour(208), BG: convert.RGBColour(255, 135, 0)},
+			expectedParams: "38;5;208;48;2;255;135;0",
+			expected:       "\x1b[0;38;5;208;48;2;255;135;0m",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.Assert(tc.expectedParams, tc.input.Params(), t)
+			test.Assert(tc.expected, tc.input.String(), t)
+			// the serialised style parses back to the same style
+			test.Assert(tc.input, convert.ParseStyle(tc.input.String()), t)
+		})
+	}
+}
+
+func TestTokenStyle(t *testing.T) {
+	token := convert.ANSILineToken{FG: "\x1b[1m\x1b[38;2;224;224;224m", BG: "\x1b[40m", T: "a"}
+	expected := convert.Style{FG: convert.RGBColour(224, 224, 224), BG: convert.IndexedColour(0), Bold: true}
+
+	test.Assert(expected, token.Style(), t)
+	test.Assert(false, token.Style().IsDefault(), t)
+	test.Assert(true, convert.ANSILineToken{T: "a"}.Style().IsDefault(), t)
+}
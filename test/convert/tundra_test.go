@@ -0,0 +1,139 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDecodeTundra(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:  "Colour opcodes",
+			input: []byte("\x06a\x00\xff\x0a\x14\x00\x00\x00\x5ab\x02c\x00\x01\x02\x03\x04\x02\x00\x00\x00\x00d"),
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[38;2;255;10;20m", BG: "\x1b[48;2;0;0;90m", T: "ab"},
+					{FG: "\x1b[38;2;1;2;3m", BG: "\x1b[48;2;0;0;90m", T: "c"},
+					{FG: "\x1b[38;2;1;2;3m", BG: "\x1b[48;2;0;0;0m", T: "☻d"},
+					{FG: "\x1b[0m", BG: "", T: strings.Repeat(" ", 75)},
+				},
+			},
+		},
+		{
+			name:  "Cursor position and wrapping",
+			input: []byte("a\x01\x00\x00\x00\x01\x00\x00\x00\x4fbc"),
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a" + strings.Repeat(" ", 79)}},
+				{{FG: "", BG: "", T: strings.Repeat(" ", 79) + "b"}},
+				{{FG: "", BG: "", T: "c" + strings.Repeat(" ", 79)}},
+			},
+		},
+		{
+			name:  "Skipped rows are empty",
+			input: []byte("a\x01\x00\x00\x00\x02\x00\x00\x00\x00b"),
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a" + strings.Repeat(" ", 79)}},
+				{},
+				{{FG: "", BG: "", T: "b" + strings.Repeat(" ", 79)}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := convert.DecodeTundra(append([]byte(convert.TundraID), tc.input...))
+
+			test.Assert(nil, err, t)
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestDecodeTundraErrors(t *testing.T) {
+	_, err := convert.DecodeTundra([]byte("TUNDRA24"))
+	test.Assert("no valid TundraDraw header found", err.Error(), t)
+
+	_, err = convert.DecodeTundra([]byte(convert.TundraID + "ab\x02c\x00\xff"))
+	test.Assert("TundraDraw data too short, expected 5 bytes after opcode 0x02 at 11", err.Error(), t)
+
+	_, err = convert.DecodeTundra([]byte(convert.TundraID + "\x01\x00\x00\x00\x00\x00\x00\x00\x50"))
+	test.Assert("invalid TundraDraw position 0,80 at 9", err.Error(), t)
+
+	_, err = convert.DecodeTundra([]byte(convert.TundraID + "\x01\xff\xff\xff\xff\x00\x00\x00\x00"))
+	test.Assert("invalid TundraDraw position 4294967295,0 at 9", err.Error(), t)
+
+	// a position at the last row only draws that row
+	result, err := convert.DecodeTundra([]byte(convert.TundraID + "\x01\x00\x00\xff\xfe\x00\x00\x00\x00a"))
+	test.Assert(nil, err, t)
+	test.Assert(0xffff, len(result), t)
+	test.Assert(0, len(result[0]), t)
+	test.Assert([]convert.ANSILineToken{{FG: "", BG: "", T: "a" + strings.Repeat(" ", 79)}}, result[0xfffe], t)
+}
+
+func TestEncodeTundra(t *testing.T) {
+	lines := [][]convert.ANSILineToken{
+		{
+			{FG: "\x1b[38;2;255;10;20m", BG: "\x1b[48;2;0;0;90m", T: "ab"},
+			{FG: "\x1b[38;2;1;2;3m", BG: "\x1b[48;2;0;0;90m", T: "c"},
+			{FG: "\x1b[38;2;1;2;3m", BG: "\x1b[44m", T: "\x02"},
+		},
+		{{FG: "", BG: "", T: "d"}},
+	}
+	expected := []byte(convert.TundraID +
+		"\x06a\x00\xff\x0a\x14\x00\x00\x00\x5ab" +
+		"\x02c\x00\x01\x02\x03" +
+		"\x04\x02\x00\x00\x00\xaa" +
+		"\x01\x00\x00\x00\x01\x00\x00\x00\x00" +
+		"\x06d\x00\xaa\xaa\xaa\x00\x00\x00\x00",
+	)
+
+	result, err := convert.EncodeTundra(lines, false)
+	test.Assert(nil, err, t)
+	test.Assert(expected, result, t)
+
+	// characters with the same value as an opcode are drawn by an opcode
+	result, err = convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "", BG: "", T: "a\x06"}}}, false)
+	test.Assert(nil, err, t)
+	test.Assert([]byte(convert.TundraID+"\x06a\x00\xaa\xaa\xaa\x00\x00\x00\x00\x02\x06\x00\xaa\xaa\xaa"), result, t)
+
+	// the round trip keeps the truecolor colours
+	data, _ := convert.EncodeTundra(lines, false)
+	decoded, err := convert.DecodeTundra(data)
+	test.Assert(nil, err, t)
+	test.Assert(lines[0][:2], decoded[0][:2], t)
+
+	// bold is a bright foreground, and blink is a bright background with iCE colour (as for --to bin)
+	bright, _ := convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "\x1b[91m", BG: "\x1b[104m", T: "a"}}}, false)
+	result, err = convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "\x1b[1;31m", BG: "\x1b[5;44m", T: "a"}}}, true)
+	test.Assert(nil, err, t)
+	test.Assert(bright, result, t)
+	result, _ = convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "\x1b[1;31m", BG: "\x1b[5;44m", T: "a"}}}, false)
+	normal, _ := convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "\x1b[91m", BG: "\x1b[44m", T: "a"}}}, false)
+	test.Assert(normal, result, t)
+
+	_, err = convert.EncodeTundra([][]convert.ANSILineToken{{{FG: "", BG: "", T: strings.Repeat("a", 81)}}}, false)
+	test.Assert("invalid width 81, TundraDraw files are 80 columns wide", err.Error(), t)
+}
+
+func TestConvertAnsTundra(t *testing.T) {
+	sauce := convert.SAUCE{
+		DataType: convert.DataTypeCharacter,
+		FileType: convert.FileTypeCharacterTundraDraw,
+	}
+	// the input is CP437 decoded, where 0xff is a non-breaking space
+	input := convert.TundraID + "\x06a\x00\u00a0\x0a\x14\x00\x00\x00\x5a"
+	expected := "\x1b[38;2;255;10;20m\x1b[48;2;0;0;90ma\x1b[0m" + strings.Repeat(" ", 79) + "\x1b[0m\n"
+
+	result := convert.ConvertAns(input, sauce)
+
+	test.PrintSimpleTestResults(input, expected, result, t)
+	test.Assert(expected, result, t)
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestUpsampleColours(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [][]convert.ANSILineToken
+		palette  convert.Palette
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name: "16 colours with the VGA palette",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "a"}, {FG: "\x1b[96m", BG: "\x1b[103m", T: "b"}},
+			},
+			palette: convert.VGAPalette,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[0;38;2;170;0;0m", BG: "\x1b[48;2;0;0;170m", T: "a"},
+					{FG: "\x1b[0;38;2;85;255;255m", BG: "\x1b[48;2;255;255;85m", T: "b"},
+				},
+			},
+		},
+		{
+			name: "Bold makes the foreground bright",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[0;1;32m", BG: "", T: "a"}, {FG: "\x1b[33;1m", BG: "", T: "b"}, {FG: "\x1b[1;22;34m", BG: "", T: "c"}},
+			},
+			palette: convert.VGAPalette,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[0;1;38;2;85;255;85m", BG: "\x1b[48;2;0;0;0m", T: "a"},
+					{FG: "\x1b[0;1;38;2;255;255;85m", BG: "\x1b[48;2;0;0;0m", T: "b"},
+					{FG: "\x1b[0;38;2;0;0;170m", BG: "\x1b[48;2;0;0;0m", T: "c"},
+				},
+			},
+		},
+		{
+			name: "Bold is tracked across the FG & BG codes",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[1;44m", T: "a"}, {FG: "\x1b[1m\x1b[39m", BG: "", T: "b"}},
+			},
+			palette: convert.VGAPalette,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[0;1;38;2;255;85;85m", BG: "\x1b[48;2;0;0;170m", T: "a"},
+					{FG: "\x1b[0;1;38;2;255;255;255m", BG: "\x1b[48;2;0;0;0m", T: "b"},
+				},
+			},
+		},
+		{
+			name: "256 colours, defaults and resets",
+			input: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[38;5;1m", BG: "\x1b[48;5;196m", T: "a"},
+					{FG: "\x1b[39m", BG: "\x1b[49m", T: "b"},
+					{FG: "\x1b[0m", BG: "", T: "c"},
+					{FG: "", BG: "", T: "d"},
+				},
+			},
+			palette: convert.XtermPalette,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[0;38;2;205;0;0m", BG: "\x1b[48;2;255;0;0m", T: "a"},
+					{FG: "\x1b[0;38;2;229;229;229m", BG: "\x1b[48;2;0;0;0m", T: "b"},
+					{FG: "\x1b[0;38;2;229;229;229m", BG: "\x1b[48;2;0;0;0m", T: "c"},
+					{FG: "\x1b[0;38;2;229;229;229m", BG: "\x1b[48;2;0;0;0m", T: "d"},
+				},
+			},
+		},
+		{
+			name: "Truecolor values are unchanged",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1m\x1b[38;2;1;2;3m", BG: "\x1b[48;2;4;5;6m", T: "a"}},
+			},
+			palette: convert.SolarizedPalette,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[0;1;38;2;1;2;3m", BG: "\x1b[48;2;4;5;6m", T: "a"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.UpsampleColours(tc.input, tc.palette)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestParsePaletteJSON(t *testing.T) {
+	data := []byte(`{"colours": [
+		"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
+		"808080", "ff0000", "00ff00", "ffff00", "0000ff", "ff00ff", "00ffff", "FFFFFF"
+	]}`)
+	palette, err := convert.ParsePaletteJSON(data)
+
+	test.Assert(nil, err, t)
+	test.Assert(convert.WindowsXPPalette, palette, t)
+
+	_, err = convert.ParsePaletteJSON([]byte(`{"colours": ["#000000"]}`))
+	test.Assert("palette must have 16 colours, found 1", err.Error(), t)
+
+	_, err = convert.ParsePaletteJSON([]byte(`{"colours": ["#00000g", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""]}`))
+	test.Assert(`invalid colour "#00000g", expected #rrggbb format`, err.Error(), t)
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+// xbinHeader returns an XBin header for an image of width x height characters
+func xbinHeader(width, height, fontHeight int, flags byte) []byte {
+	return append([]byte(convert.XBinID), byte(width), byte(width>>8), byte(height), byte(height>>8), byte(fontHeight), flags)
+}
+
+func TestParseXBin(t *testing.T) {
+	palette := bytes.Repeat([]byte{0, 0, 0}, 16)
+	copy(palette[3:], []byte{63, 32, 1})
+	font := make([]byte, 256*2)
+	font['A'*2], font['A'*2+1] = 0x18, 0x24
+
+	data := xbinHeader(2, 1, 2, convert.XBinFlagPalette|convert.XBinFlagFont)
+	data = append(append(append(data, palette...), font...), 'A', 0x01, 'B', 0x10)
+
+	result, err := convert.ParseXBin(data)
+	test.Assert(nil, err, t)
+	test.Assert(2, result.Width, t)
+	test.Assert(1, result.Height, t)
+	test.Assert(2, result.FontHeight, t)
+	test.Assert(convert.RGB{R: 0xff, G: 0x82, B: 0x04}, result.Palette[1], t)
+	test.Assert(256, len(result.Font), t)
+	test.Assert([]byte{0x18, 0x24}, result.Font['A'], t)
+	test.Assert([]byte{'A', 0x01, 'B', 0x10}, result.Data, t)
+}
+
+func TestParseXBinCompressed(t *testing.T) {
+	data := append(xbinHeader(4, 3, 16, convert.XBinFlagCompress),
+		0x01, 'a', 0x07, 'b', 0x07, // 2 uncompressed pairs
+		0x41, 'c', 0x01, 0x02, // character compression
+		0x82, 0x03, 'd', 'e', 'f', // attribute compression
+		0xc4, 'g', 0x04, // character & attribute compression, across 2 lines
+	)
+	expected := []byte("a\x07b\x07c\x01c\x02d\x03e\x03f\x03g\x04g\x04g\x04g\x04g\x04")
+
+	result, err := convert.ParseXBin(data)
+	test.Assert(nil, err, t)
+	test.Assert(expected, result.Data, t)
+
+	_, err = convert.ParseXBin(data[:len(data)-1])
+	test.Assert("XBin image data too short, expected 24 bytes but found 14", err.Error(), t)
+}
+
+func TestParseXBinErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{
+			name:     "No header",
+			input:    []byte("XBIN"),
+			expected: "no valid XBin header found",
+		},
+		{
+			name:     "Truncated palette",
+			input:    append(xbinHeader(1, 1, 16, convert.XBinFlagPalette), make([]byte, 47)...),
+			expected: "XBin data too short to contain a palette",
+		},
+		{
+			name:     "Truncated font",
+			input:    append(xbinHeader(1, 1, 8, convert.XBinFlagFont|convert.XBinFlag512Chars), make([]byte, 256*8)...),
+			expected: "XBin data too short to contain a 512 character font",
+		},
+		{
+			name:     "Truncated uncompressed image",
+			input:    append(xbinHeader(2, 2, 16, 0), 'a', 0x07),
+			expected: "XBin image data too short, expected 8 bytes but found 2",
+		},
+		{
+			name:     "Compressed image larger than the data",
+			input:    append(xbinHeader(0xffff, 0xffff, 16, convert.XBinFlagCompress), 0xc0, 'a', 0x07),
+			expected: "XBin image data too short, expected 8589672450 bytes but found 2",
+		},
+		{
+			name:     "Truncated compressed run",
+			input:    append(xbinHeader(4, 1, 16, convert.XBinFlagCompress), 0x03, 'a', 0x07, 'b'),
+			expected: "XBin image data too short, expected 8 bytes but found 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := convert.ParseXBin(tc.input)
+			test.Assert(tc.expected, err.Error(), t)
+		})
+	}
+}
+
+func TestXBinTokens(t *testing.T) {
+	palette := &convert.Palette{{R: 0, G: 0, B: 0}, {R: 255, G: 130, B: 4}, {R: 1, G: 2, B: 3}}
+
+	testCases := []struct {
+		name     string
+		input    convert.XBin
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:  "Truecolor from the palette, with iCE colour",
+			input: convert.XBin{Width: 2, Height: 1, Flags: convert.XBinFlagNonBlink, Palette: palette, Data: []byte("A\x01\x01\x92")},
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[38;2;255;130;4m", BG: "\x1b[48;2;0;0;0m", T: "A"},
+					{FG: "\x1b[38;2;1;2;3m", BG: "\x1b[48;2;0;0;0m", T: "☺"},
+				},
+			},
+		},
+		{
+			name:  "16 colours without a palette, with blink",
+			input: convert.XBin{Width: 1, Height: 2, Data: []byte("A\x9e\xdb\x1f")},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5m\x1b[93m", BG: "\x1b[44m", T: "A"}},
+				{{FG: "\x1b[97m", BG: "\x1b[44m", T: "█"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.input.Tokens()
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
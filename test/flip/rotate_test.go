@@ -0,0 +1,126 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestRotate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []string
+		degrees  int
+		expected []string
+	}{
+		{
+			name:     "Text rotated by 90 degrees",
+			input:    []string{"abc", "de"},
+			degrees:  90,
+			expected: []string{"da", "eb", " c"},
+		},
+		{
+			name:     "Text rotated by 270 degrees",
+			input:    []string{"abc", "de"},
+			degrees:  270,
+			expected: []string{"c ", "be", "ad"},
+		},
+		{
+			name:     "Text rotated by 180 degrees",
+			input:    []string{"abc", "de"},
+			degrees:  180,
+			expected: []string{" ed", "cba"},
+		},
+		{
+			name: "Box rotated by 90 degrees",
+			input: []string{
+				"┌──┐",
+				"│▀▌│",
+				"└──┘",
+			},
+			degrees: 90,
+			expected: []string{
+				"┌─┐",
+				"│▐│",
+				"│▀│",
+				"└─┘",
+			},
+		},
+		{
+			name: "Box rotated by 180 degrees",
+			input: []string{
+				"╔═╤╗",
+				"╟▘─╢",
+				"╚═╧╝",
+			},
+			degrees: 180,
+			expected: []string{
+				"╔╤═╗",
+				"╟─▗╢",
+				"╚╧═╝",
+			},
+		},
+		{
+			name: "Box rotated by 270 degrees",
+			input: []string{
+				"╭→╮",
+				"╰┬╯",
+			},
+			degrees: 270,
+			expected: []string{
+				"╭╮",
+				"↑├",
+				"╰╯",
+			},
+		},
+		{
+			name:     "Double-width runes keep their place when rotated by 180 degrees",
+			input:    []string{"a中b", "cd"},
+			degrees:  180,
+			expected: []string{"  dc", "b中a"},
+		},
+		{
+			name:     "Double-width runes become spaces when rotated by 90 degrees",
+			input:    []string{"a中b", "cd"},
+			degrees:  90,
+			expected: []string{"ca", "d ", "  ", " b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := convert.TokeniseANSIString(strings.Join(tc.input, "\n"))
+			result, err := convert.Rotate(input, tc.degrees)
+
+			expected := [][]convert.ANSILineToken{}
+			for _, line := range tc.expected {
+				expected = append(expected, []convert.ANSILineToken{{FG: "", BG: "", T: line}})
+			}
+			test.Assert(nil, err, t)
+			test.PrintANSITestResults(strings.Join(tc.input, "\n"), expected, result, t)
+			test.Assert(expected, result, t)
+		})
+	}
+}
+
+func TestRotateColours(t *testing.T) {
+	input := [][]convert.ANSILineToken{
+		{{FG: "\x1b[31m", BG: "", T: "▀"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "▀"}},
+		{{FG: "\x1b[33m", BG: "", T: "▄"}, {FG: "", BG: "", T: " "}},
+	}
+	expected := [][]convert.ANSILineToken{
+		{{FG: "\x1b[33m", BG: "", T: "▌"}, {FG: "\x1b[31m", BG: "", T: "▐"}},
+		{{FG: "", BG: "", T: " "}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "▐"}},
+	}
+	result, err := convert.Rotate(input, 90)
+
+	test.Assert(nil, err, t)
+	test.Assert(expected, result, t)
+}
+
+func TestRotateInvalid(t *testing.T) {
+	_, err := convert.Rotate([][]convert.ANSILineToken{{{FG: "", BG: "", T: "a"}}}, 45)
+	test.Assert("invalid rotation 45, expected 90, 180 or 270", err.Error(), t)
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestGIF(t *testing.T) {
+	red, black := color.RGBA{0xaa, 0x00, 0x00, 0xff}, color.RGBA{0x00, 0x00, 0x00, 0xff}
+
+	testCases := []struct {
+		name   string
+		input  [][][]convert.ANSILineToken
+		tflags byte
+		width  int
+		height int
+		// the colour of the top left pixel of each frame
+		pixels []color.RGBA
+	}{
+		{
+			name:   "Single frame",
+			input:  [][][]convert.ANSILineToken{{{{FG: "\x1b[31m", BG: "", T: "█ "}}}},
+			width:  16,
+			height: 16,
+			pixels: []color.RGBA{red},
+		},
+		{
+			name:   "Blinking text alternates",
+			input:  [][][]convert.ANSILineToken{{{{FG: "\x1b[5m\x1b[31m", BG: "", T: "█"}}}},
+			width:  8,
+			height: 16,
+			pixels: []color.RGBA{red, black},
+		},
+		{
+			name:   "Blinking text with iCE colour",
+			input:  [][][]convert.ANSILineToken{{{{FG: "\x1b[5m\x1b[31m", BG: "", T: "█"}}}},
+			tflags: convert.ANSiFlagNonBlinkMode,
+			width:  8,
+			height: 16,
+			pixels: []color.RGBA{red},
+		},
+		{
+			name: "Frames are drawn at the size of the largest, with blinking text on each",
+			input: [][][]convert.ANSILineToken{
+				{{{FG: "\x1b[5m\x1b[31m", BG: "", T: "█"}}},
+				{{{FG: "\x1b[5m\x1b[31m", BG: "", T: "█"}}, {{FG: "", BG: "", T: "abc"}}},
+				{{{FG: "\x1b[5m\x1b[31m", BG: "", T: "█"}}},
+			},
+			width:  24,
+			height: 32,
+			pixels: []color.RGBA{red, red, red},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := render.GIF(tc.input, &convert.SAUCE{TFlags: tc.tflags}, nil, 250*time.Millisecond)
+			test.Assert(nil, err, t)
+
+			result, err := gif.DecodeAll(bytes.NewReader(data))
+			test.Assert(nil, err, t)
+			test.Assert(tc.width, result.Config.Width, t)
+			test.Assert(tc.height, result.Config.Height, t)
+			test.Assert(len(tc.pixels), len(result.Image), t)
+			for i, expected := range tc.pixels {
+				test.Assert(25, result.Delay[i], t)
+				test.Assert(tc.width, result.Image[i].Bounds().Dx(), t)
+				test.Assert(color.Color(expected), result.Image[i].At(0, 0), t)
+			}
+		})
+	}
+}
+
+func TestGIFManyColours(t *testing.T) {
+	// more than 256 colours are dithered to a fixed palette
+	line := make([]convert.ANSILineToken, 0, 300)
+	for i := range 300 {
+		line = append(line, convert.ANSILineToken{FG: fmt.Sprintf("\x1b[38;2;%d;%d;0m", i%256, i/256*255), BG: "", T: "█"})
+	}
+	data, err := render.GIF([][][]convert.ANSILineToken{{line}}, &convert.SAUCE{}, nil, 0)
+	test.Assert(nil, err, t)
+
+	result, err := gif.DecodeAll(bytes.NewReader(data))
+	test.Assert(nil, err, t)
+	test.Assert(2400, result.Config.Width, t)
+	test.Assert(256, len(result.Image[0].Palette), t)
+}
+
+func TestGIFDelay(t *testing.T) {
+	testCases := []struct {
+		name     string
+		delay    time.Duration
+		expected int
+	}{
+		{name: "Rounded down to 10ms", delay: 125 * time.Millisecond, expected: 12},
+		{name: "Less than 10ms", delay: 5 * time.Millisecond, expected: 1},
+		{name: "No delay", delay: 0, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := [][][]convert.ANSILineToken{{{{FG: "", BG: "", T: "a"}}}, {{{FG: "", BG: "", T: "b"}}}}
+			data, err := render.GIF(input, &convert.SAUCE{}, nil, tc.delay)
+			test.Assert(nil, err, t)
+
+			result, err := gif.DecodeAll(bytes.NewReader(data))
+			test.Assert(nil, err, t)
+			test.Assert([]int{tc.expected, tc.expected}, result.Delay, t)
+		})
+	}
+}
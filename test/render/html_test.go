@@ -0,0 +1,106 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+// htmlBody returns the contents of the <pre> block of a rendered HTML document
+func htmlBody(doc string) string {
+	_, body, _ := strings.Cut(doc, `<pre class="ansi">`)
+	body, _, _ = strings.Cut(body, "</pre>")
+	return body
+}
+
+func TestHTML(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      [][]convert.ANSILineToken
+		sauce      *convert.SAUCE
+		body       string
+		stylesheet []string
+	}{
+		{
+			name: "Plain text is escaped",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "<a & b>"}},
+			},
+			sauce:      &convert.SAUCE{},
+			body:       "&lt;a &amp; b&gt;",
+			stylesheet: []string{},
+		},
+		{
+			name: "16 colours with adjacent runs merged",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "ab"}, {FG: "\x1b[31m", BG: "\x1b[44m", T: "c"}, {FG: "\x1b[0m", BG: "", T: "d"}},
+				{{FG: "\x1b[96m", BG: "", T: "e"}},
+			},
+			sauce:      &convert.SAUCE{},
+			body:       "<span class=\"fg-1 bg-4\">abc</span>d\n<span class=\"fg-14\">e</span>",
+			stylesheet: []string{".fg-1 { color: #aa0000; }", ".bg-4 { background-color: #0000aa; }", ".fg-14 { color: #55ffff; }"},
+		},
+		{
+			name: "256 colours and truecolor",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;5;208m", BG: "\x1b[48;2;1;2;3m", T: "x"}},
+			},
+			sauce:      &convert.SAUCE{},
+			body:       `<span class="fg-208 bg-010203">x</span>`,
+			stylesheet: []string{".fg-208 { color: #ff8700; }", ".bg-010203 { background-color: #010203; }"},
+		},
+		{
+			name: "Bold brightens the foreground",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1m\x1b[32m", BG: "", T: "x"}},
+			},
+			sauce:      &convert.SAUCE{},
+			body:       `<span class="fg-10">x</span>`,
+			stylesheet: []string{".fg-10 { color: #55ff55; }"},
+		},
+		{
+			name: "Blink without iCE colour",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5;33m", BG: "\x1b[41m", T: "x"}},
+			},
+			sauce:      &convert.SAUCE{},
+			body:       `<span class="fg-3 bg-1 blink">x</span>`,
+			stylesheet: []string{".blink { animation: blink 1s step-end infinite; }", "@keyframes blink"},
+		},
+		{
+			name: "Blink with iCE colour is a bright background",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5;33m", BG: "\x1b[41m", T: "x"}},
+			},
+			sauce:      &convert.SAUCE{TFlags: convert.ANSiFlagNonBlinkMode},
+			body:       `<span class="fg-3 bg-9">x</span>`,
+			stylesheet: []string{".bg-9 { background-color: #ff5555; }"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := render.HTML(tc.input, tc.sauce)
+
+			test.Assert(tc.body, htmlBody(result), t)
+			for _, rule := range tc.stylesheet {
+				test.Assert(true, strings.Contains(result, rule), t)
+			}
+			if !strings.Contains(tc.body, "blink") {
+				test.Assert(false, strings.Contains(result, "@keyframes"), t)
+			}
+		})
+	}
+}
+
+func TestHTMLDocument(t *testing.T) {
+	sauce := &convert.SAUCE{Title: "Fish & Chips"}
+	result := render.HTML([][]convert.ANSILineToken{{{FG: "", BG: "", T: "x"}}}, sauce)
+
+	test.Assert(true, strings.HasPrefix(result, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n"), t)
+	test.Assert(true, strings.Contains(result, "<title>Fish &amp; Chips</title>"), t)
+	test.Assert(true, strings.HasSuffix(result, "</pre>\n</body>\n</html>\n"), t)
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestCP437Byte(t *testing.T) {
+	testCases := []struct {
+		input    rune
+		expected byte
+		ok       bool
+	}{
+		{'A', 0x41, true},
+		{'☺', 0x01, true},
+		{'▼', 0x1F, true},
+		{'⌂', 0x7F, true},
+		{'Ç', 0x80, true},
+		{'░', 0xB0, true},
+		{'▀', 0xDF, true},
+		{'■', 0xFE, true},
+		{'世', '?', false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.input), func(t *testing.T) {
+			result, ok := render.CP437Byte(tc.input)
+
+			test.Assert(tc.expected, result, t)
+			test.Assert(tc.ok, ok, t)
+		})
+	}
+}
+
+func TestVGAFontPixel(t *testing.T) {
+	font := render.VGAFont(9)
+
+	// full block
+	test.Assert(true, font.Pixel(0xDB, 0, 0), t)
+	test.Assert(true, font.Pixel(0xDB, 8, 15), t)
+	// the 9th column repeats the 8th column of line-drawing glyphs only
+	test.Assert(true, font.Pixel(0xC4, 8, 7), t)
+	test.Assert(false, font.Pixel(0xC4, 8, 6), t)
+	test.Assert(false, font.Pixel(0xB2, 8, 0), t)
+	// 'A' is blank at the top and bottom
+	test.Assert(false, font.Pixel('A', 3, 0), t)
+	test.Assert(true, font.Pixel('A', 3, 2), t)
+}
+
+func TestImage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  [][]convert.ANSILineToken
+		tflags byte
+		width  int
+		height int
+		pixels map[[2]int]color.RGBA
+	}{
+		{
+			name:   "8 pixel font",
+			input:  [][]convert.ANSILineToken{{{FG: "\x1b[31m", BG: "\x1b[44m", T: "█ "}}},
+			width:  16,
+			height: 16,
+			pixels: map[[2]int]color.RGBA{
+				{0, 0}:  {0xaa, 0x00, 0x00, 0xff},
+				{8, 0}:  {0x00, 0x00, 0xaa, 0xff},
+				{15, 8}: {0x00, 0x00, 0xaa, 0xff},
+			},
+		},
+		{
+			name:   "9 pixel font",
+			input:  [][]convert.ANSILineToken{{{FG: "", BG: "", T: "─"}}, {{FG: "", BG: "", T: "x"}}},
+			tflags: 0x04,
+			width:  9,
+			height: 32,
+			pixels: map[[2]int]color.RGBA{
+				{8, 7}: {0xaa, 0xaa, 0xaa, 0xff},
+				{8, 6}: {0x00, 0x00, 0x00, 0xff},
+			},
+		},
+		{
+			name:   "Stretched aspect ratio",
+			input:  [][]convert.ANSILineToken{{{FG: "", BG: "", T: "ab"}}},
+			tflags: 0x02 | 0x08,
+			width:  16,
+			height: 19,
+		},
+		{
+			name:   "Bold and iCE colour",
+			input:  [][]convert.ANSILineToken{{{FG: "\x1b[1;5;32m", BG: "\x1b[41m", T: "█ "}}},
+			tflags: convert.ANSiFlagNonBlinkMode,
+			width:  16,
+			height: 16,
+			pixels: map[[2]int]color.RGBA{
+				{0, 0}: {0x55, 0xff, 0x55, 0xff},
+				{8, 0}: {0xff, 0x55, 0x55, 0xff},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sauce := &convert.SAUCE{TFlags: tc.tflags}
+			result := render.Image(tc.input, sauce, nil)
+
+			test.Assert(tc.width, result.Bounds().Dx(), t)
+			test.Assert(tc.height, result.Bounds().Dy(), t)
+			for pos, expected := range tc.pixels {
+				test.Assert(expected, result.RGBAAt(pos[0], pos[1]), t)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	data, err := render.PNG([][]convert.ANSILineToken{{{FG: "", BG: "", T: "abc"}}}, &convert.SAUCE{}, nil)
+	test.Assert(nil, err, t)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	test.Assert(nil, err, t)
+	test.Assert(24, img.Bounds().Dx(), t)
+	test.Assert(16, img.Bounds().Dy(), t)
+}
+
+func TestFontForSAUCE(t *testing.T) {
+	testCases := []struct {
+		name     string
+		font     string
+		width    int
+		height   int
+		expected string
+	}{
+		{name: "Default", font: "", width: 8, height: 16},
+		{name: "IBM VGA with a code page", font: "IBM VGA 437", width: 8, height: 16},
+		{name: "IBM VGA50", font: "IBM VGA50", width: 8, height: 8},
+		{name: "IBM EGA", font: "IBM EGA", width: 8, height: 14},
+		{name: "IBM VGA25G", font: "IBM VGA25G 437", width: 8, height: 19},
+		{name: "Amiga", font: "Amiga Topaz 1", width: 8, height: 16, expected: "font \"Amiga Topaz 1\" is not built in, using IBM VGA"},
+		{name: "Other code page", font: "IBM EGA 850", width: 8, height: 16, expected: "font \"IBM EGA 850\" is not built in, using IBM VGA"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sauce := &convert.SAUCE{TInfoS: tc.font}
+			font, err := render.FontForSAUCE(sauce)
+			if tc.expected == "" {
+				test.Assert(nil, err, t)
+			} else {
+				test.Assert(tc.expected, err.Error(), t)
+			}
+			test.Assert(tc.width, font.Width, t)
+			test.Assert(tc.height, font.Height, t)
+
+			img := render.Image([][]convert.ANSILineToken{{{FG: "", BG: "", T: "ab"}}}, sauce, nil)
+			test.Assert(tc.width*2, img.Bounds().Dx(), t)
+			test.Assert(tc.height, img.Bounds().Dy(), t)
+		})
+	}
+}
+
+func TestXBinFont(t *testing.T) {
+	glyphs := make([][]byte, 256)
+	for i := range glyphs {
+		glyphs[i] = make([]byte, 8)
+	}
+	glyphs['x'][0] = 0x80
+	font := render.XBinFont(&convert.XBin{FontHeight: 8, Font: glyphs})
+
+	test.Assert(8, font.Width, t)
+	test.Assert(8, font.Height, t)
+	test.Assert(true, font.Pixel('x', 0, 0), t)
+	test.Assert(false, font.Pixel('x', 1, 0), t)
+
+	img := render.Image([][]convert.ANSILineToken{{{FG: "", BG: "", T: "xx"}}}, &convert.SAUCE{}, font)
+	test.Assert(16, img.Bounds().Dx(), t)
+	test.Assert(8, img.Bounds().Dy(), t)
+
+	test.Assert((*render.Font)(nil), render.XBinFont(&convert.XBin{FontHeight: 16}), t)
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestSVGGeometry(t *testing.T) {
+	input := [][]convert.ANSILineToken{
+		{{FG: "", BG: "", T: "abcd"}},
+		{{FG: "", BG: "", T: "ef"}},
+	}
+	testCases := []struct {
+		name     string
+		tflags   byte
+		expected string
+	}{
+		{
+			name:     "Legacy letter spacing uses 8 pixel cells",
+			tflags:   0x00,
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="32" height="32" viewBox="0 0 32 32" preserveAspectRatio="none">`,
+		},
+		{
+			name:     "9 pixel letter spacing",
+			tflags:   0x04,
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="36" height="32" viewBox="0 0 36 32" preserveAspectRatio="none">`,
+		},
+		{
+			name:     "8 pixel letter spacing with stretched aspect ratio",
+			tflags:   0x02 | 0x08,
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="32" height="38.4" viewBox="0 0 32 32" preserveAspectRatio="none">`,
+		},
+		{
+			name:     "9 pixel letter spacing with stretched aspect ratio",
+			tflags:   0x04 | 0x08,
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="36" height="43.2" viewBox="0 0 36 32" preserveAspectRatio="none">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := render.SVG(input, &convert.SAUCE{TFlags: tc.tflags})
+			header, _, _ := strings.Cut(result, "\n")
+
+			test.Assert(tc.expected, header, t)
+		})
+	}
+}
+
+func TestSVGCells(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [][]convert.ANSILineToken
+		tflags   byte
+		expected []string
+	}{
+		{
+			name: "Background runs share a rect and spaces have no glyph",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "a b"}, {FG: "\x1b[0m", BG: "", T: "c"}},
+			},
+			expected: []string{
+				`<rect x="0" y="0" width="24" height="16" fill="#0000aa"/>`,
+				`<text x="4" y="12" fill="#aa0000">a</text>`,
+				`<text x="20" y="12" fill="#aa0000">b</text>`,
+				`<text x="28" y="12" fill="#aaaaaa">c</text>`,
+			},
+		},
+		{
+			name: "Glyphs are escaped and double-width runes span two cells",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "<世"}},
+			},
+			expected: []string{
+				`<text x="4" y="12" fill="#aaaaaa">&lt;</text>`,
+				`<text x="16" y="12" fill="#aaaaaa">世</text>`,
+			},
+		},
+		{
+			name: "Blink without iCE colour",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5;32m", BG: "\x1b[41m", T: "x"}},
+			},
+			expected: []string{
+				`<rect x="0" y="0" width="8" height="16" fill="#aa0000"/>`,
+				`<text x="4" y="12" fill="#00aa00" class="blink">x</text>`,
+				`@keyframes blink`,
+			},
+		},
+		{
+			name: "Blink with iCE colour is a bright background",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[5;32m", BG: "\x1b[41m", T: "x"}},
+			},
+			tflags: convert.ANSiFlagNonBlinkMode,
+			expected: []string{
+				`<rect x="0" y="0" width="8" height="16" fill="#ff5555"/>`,
+				`<text x="4" y="12" fill="#00aa00">x</text>`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := render.SVG(tc.input, &convert.SAUCE{TFlags: tc.tflags})
+
+			for _, element := range tc.expected {
+				if !strings.Contains(result, element) {
+					test.Fail(element, result, t)
+				}
+			}
+		})
+	}
+}
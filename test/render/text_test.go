@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/render"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestText(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		flip      bool
+		trimRight bool
+		expected  string
+	}{
+		{
+			name:     "Colours are removed",
+			input:    "\x1b[31mab\x1b[44mc\x1b[0m\n\x1b[38;2;1;2;3md\x1b[0m\n",
+			expected: "abc\nd\n",
+		},
+		{
+			name:     "Cursor forward, control characters and carriage returns",
+			input:    "\x1b[31ma\x1b[3Cb\x07c  \r\n",
+			expected: "a   b c  \n",
+		},
+		{
+			name:     "Double width runes",
+			input:    "\x1b[44m世界 \x1b[0m\n",
+			expected: "世界 \n",
+		},
+		{
+			name:      "Trailing spaces are trimmed",
+			input:     "\x1b[31ma\x1b[3Cb  \x1b[44m  \x1b[0m\n  c \n",
+			trimRight: true,
+			expected:  "a   b\n  c\n",
+		},
+		{
+			name:      "Flipped with the mirror map",
+			input:     "\x1b[31m(ab]\x1b[0m\nx\n",
+			flip:      true,
+			trimRight: true,
+			expected:  "[dɒ)\n   x\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := convert.TokeniseANSIString(tc.input)
+			if tc.flip {
+				lines = convert.FlipHorizontal(lines)
+			}
+			result := render.Text(lines, tc.trimRight)
+
+			test.PrintSimpleTestResults(tc.input, tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
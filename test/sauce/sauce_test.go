@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -357,3 +358,69 @@ func TestParseFileDataWithEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSAUCEComments(t *testing.T) {
+	record := (&convert.SAUCE{Title: "Commented"}).ToBytes()[1:]
+	record[104] = 2 // Comments (1 byte)
+
+	testCases := []struct {
+		name             string
+		input            []byte
+		expectedComments []string
+		expectedData     string
+	}{
+		{
+			name: "Valid comment block",
+			input: slices.Concat(
+				[]byte("art\x1a"),
+				[]byte("COMNT"),
+				[]byte("first comment"+strings.Repeat(" ", 51)),
+				[]byte("second comment"+strings.Repeat(" ", 50)),
+				record,
+			),
+			expectedComments: []string{"first comment", "second comment"},
+			expectedData:     "art",
+		},
+		{
+			name: "CP437 comment block",
+			input: slices.Concat(
+				[]byte("art\x1a"),
+				[]byte("COMNT"),
+				[]byte("caf\x82 \xb0\xb1\xb2"+strings.Repeat(" ", 56)),
+				[]byte("\x9c5 \xaf"+strings.Repeat("\x00", 60)),
+				record,
+			),
+			expectedComments: []string{"café ░▒▓", "£5 »"},
+			expectedData:     "art",
+		},
+		{
+			name: "Missing comment block",
+			input: slices.Concat(
+				[]byte("art"+strings.Repeat(" ", 5+64*2)+"\x1a"),
+				record,
+			),
+			expectedComments: nil,
+			expectedData:     "art" + strings.Repeat(" ", 5+64*2),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, data, err := convert.ParseSAUCE(tc.input, "ascii")
+			if err != nil {
+				t.Fatalf("Error parsing SAUCE record: %v", err)
+			}
+			test.Assert(tc.expectedComments, result.CommentLines, t)
+			test.Assert(tc.expectedData, data, t)
+
+			resultJSON, err := result.ToJSON()
+			if err != nil {
+				t.Fatalf("Error converting SAUCE record to JSON: %v", err)
+			}
+			for _, line := range tc.expectedComments {
+				test.Assert(true, strings.Contains(result.ToString(), line), t)
+				test.Assert(true, strings.Contains(resultJSON, line), t)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestSAUCEToBytes(t *testing.T) {
+	sauce := &convert.SAUCE{
+		ID:       "SAUCE",
+		Version:  "00",
+		Title:    "Evoke 2025",
+		Author:   "Arlequin",
+		Group:    "Impure",
+		Date:     "20250922",
+		FileSize: 4069,
+		DataType: convert.DataTypeCharacter,
+		FileType: convert.FileTypeCharacterANSI,
+		TInfo1:   convert.TInfoField{Name: "Character width", Value: 80},
+		TInfo2:   convert.TInfoField{Name: "Number of lines", Value: 25},
+		TInfo3:   convert.TInfoField{Name: "0", Value: 0},
+		TInfo4:   convert.TInfoField{Name: "0", Value: 0},
+		TFlags:   0x04,
+		TInfoS:   "IBM VGA",
+	}
+	expected := append([]byte("\x1a"), []byte{
+		'S', 'A', 'U', 'C', 'E', '0', '0', // ID + Version
+		'E', 'v', 'o', 'k', 'e', ' ', '2', '0', '2', '5', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', // Title (35 bytes)
+		'A', 'r', 'l', 'e', 'q', 'u', 'i', 'n', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', // Author (20 bytes)
+		'I', 'm', 'p', 'u', 'r', 'e', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', // Group (20 bytes)
+		'2', '0', '2', '5', '0', '9', '2', '2', // Date (8 bytes)
+		0xe5, 0x0f, 0x00, 0x00, // FileSize
+		0x01,       // DataType (Character)
+		0x01,       // FileType (ANSi)
+		0x50, 0x00, // TInfo1
+		0x19, 0x00, // TInfo2
+		0x00, 0x00, // TInfo3
+		0x00, 0x00, // TInfo4
+		0x00,                                                                                                                        // Comments
+		0x04,                                                                                                                        // TFlags
+		'I', 'B', 'M', ' ', 'V', 'G', 'A', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // TInfoS (22 bytes)
+	}...)
+
+	result := sauce.ToBytes()
+	test.Assert(expected, result, t)
+
+	// the encoded record should parse back to the original
+	parsed, data, err := convert.ParseSAUCE(append([]byte("art"), result...), "ascii")
+	if err != nil {
+		t.Fatalf("Error parsing encoded SAUCE record: %v", err)
+	}
+	test.Assert("art", data, t)
+	test.Assert(sauce, parsed, t)
+}
+
+func TestSAUCEToBytesComments(t *testing.T) {
+	sauce := &convert.SAUCE{
+		Title:        "A title that is much too long for the field",
+		CommentLines: []string{"first comment", "second comment"},
+	}
+	result := sauce.ToBytes()
+
+	test.Assert(1+5+64*2+128, len(result), t)
+	test.Assert([]byte("\x1aCOMNTfirst comment"), result[:19], t)
+	test.Assert([]byte("second comment"), result[70:84], t)
+	test.Assert([]byte("SAUCE00A title that is much too long for"), result[134:174], t)
+	test.Assert(byte(2), result[len(result)-24], t)
+	// the record itself is left unchanged
+	test.Assert(byte(0), sauce.Comments, t)
+}
+
+func TestWriteSAUCE(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Appends a record to a file without one",
+			input: []byte("\x1b[31mhello\r\n"),
+		},
+		{
+			name:  "Replaces an existing record",
+			input: append([]byte("\x1b[31mhello\r\n"), (&convert.SAUCE{Title: "Old title"}).ToBytes()...),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sauce := &convert.SAUCE{ID: "SAUCE", Version: "00", Title: "New title", Date: "20261016"}
+			result := convert.WriteSAUCE(tc.input, sauce)
+
+			test.Assert([]byte("\x1b[31mhello\r\n"), result[:len(result)-129], t)
+			test.Assert(true, bytes.Equal(convert.StripSAUCE(result), []byte("\x1b[31mhello\r\n")), t)
+
+			parsed, _, err := convert.ParseSAUCE(result, "ascii")
+			if err != nil {
+				t.Fatalf("Error parsing written SAUCE record: %v", err)
+			}
+			test.Assert("New title", parsed.Title, t)
+			test.Assert(uint32(12), parsed.FileSize, t)
+		})
+	}
+}
+
+func TestWriteSAUCERoundTrip(t *testing.T) {
+	sauce := &convert.SAUCE{
+		Title:        "Café ░▒▓",
+		Author:       "Señor",
+		Group:        "£ group",
+		TInfoS:       "IBM VGA",
+		Comments:     5,
+		CommentLines: []string{"¿comment?"},
+	}
+	result := convert.WriteSAUCE([]byte("art"), sauce)
+	test.Assert(byte(1), sauce.Comments, t)
+
+	parsed, data, err := convert.ParseSAUCE(result, "cp437")
+	if err != nil {
+		t.Fatalf("Error parsing written SAUCE record: %v", err)
+	}
+	test.Assert("art", data, t)
+	test.Assert("Café ░▒▓", parsed.Title, t)
+	test.Assert("Señor", parsed.Author, t)
+	test.Assert("£ group", parsed.Group, t)
+	test.Assert("IBM VGA", parsed.TInfoS, t)
+	test.Assert(byte(1), parsed.Comments, t)
+	test.Assert([]string{"¿comment?"}, parsed.CommentLines, t)
+}
+
+func TestSAUCESetDate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Valid date", input: "20261016", expected: ""},
+		{name: "Too short", input: "2026", expected: "invalid SAUCE date \"2026\", expected CCYYMMDD format"},
+		{name: "Invalid month", input: "20261316", expected: "invalid SAUCE date \"20261316\", expected CCYYMMDD format"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sauce := &convert.SAUCE{}
+			var result string
+			if err := sauce.SetDate(tc.input); err != nil {
+				result = err.Error()
+			}
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/screen"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestSplitFrames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "No frame boundaries",
+			input:    "abc\r\ndef",
+			expected: []string{"abc\r\ndef"},
+		},
+		{
+			name:     "Cursor home and clear screen",
+			input:    "\x1b[2J\x1b[Habc\x1b[Hd\x1b[1;1He\x1b[2Jf",
+			expected: []string{"\x1b[2J\x1b[Habc", "\x1b[Hd", "\x1b[1;1He", "\x1b[2Jf"},
+		},
+		{
+			name:     "Boundaries without any text start the same frame",
+			input:    "a\x1b[H\x1b[31m\r\n\x1b[2Jb\x1b[H\x1b[0m",
+			expected: []string{"a", "\x1b[H\x1b[31m\r\n\x1b[2Jb"},
+		},
+		{
+			name:     "Nothing after the EOF marker",
+			input:    "a\x1b[Hb\x1a\x1b[Hc",
+			expected: []string{"a", "\x1b[Hb"},
+		},
+		{
+			name:     "Cursor positions that aren't home",
+			input:    "a\x1b[2;1Hb\x1b[1;2Hc",
+			expected: []string{"a\x1b[2;1Hb\x1b[1;2Hc"},
+		},
+		{
+			name:     "Cursor positions with a repeated 1 aren't home",
+			input:    "a\x1b[11Hb\x1b[11fc\x1b[1;11Hd\x1b[;1fe",
+			expected: []string{"a\x1b[11Hb\x1b[11fc\x1b[1;11Hd", "\x1b[;1fe"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := screen.SplitFrames(tc.input)
+
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestFrames(t *testing.T) {
+	input := "\x1b[2J\x1b[31mabc\r\nde\x1b[H\x1b[32mX\x1b[2Jf"
+	expected := [][][]convert.ANSILineToken{
+		{
+			{{FG: "\x1b[31m", BG: "", T: "abc"}},
+			{{FG: "\x1b[31m", BG: "", T: "de"}},
+		},
+		{
+			// the second frame only redraws the first cell
+			{{FG: "\x1b[32m", BG: "", T: "X"}, {FG: "\x1b[31m", BG: "", T: "bc"}},
+			{{FG: "\x1b[31m", BG: "", T: "de"}},
+		},
+		{
+			{{FG: "\x1b[32m", BG: "", T: "f"}},
+		},
+	}
+
+	result := screen.Frames(input, 80)
+
+	test.Assert(expected, result, t)
+}
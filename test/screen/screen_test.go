@@ -0,0 +1,141 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/screen"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestScreenRender(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		width    int
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:  "Plain text",
+			input: "abc\ndef",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "abc"}},
+				{{FG: "", BG: "", T: "def"}},
+			},
+		},
+		{
+			name:  "Wraps at the screen width",
+			input: "\x1b[31mabcdef",
+			width: 4,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "abcd"}},
+				{{FG: "\x1b[31m", BG: "", T: "ef"}},
+			},
+		},
+		{
+			name:  "Cursor forward leaves blank cells",
+			input: "\x1b[42mXX\x1b[3CYY",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "", BG: "\x1b[42m", T: "XX"},
+					{FG: "\x1b[0m", BG: "", T: "   "},
+					{FG: "", BG: "\x1b[42m", T: "YY"},
+				},
+			},
+		},
+		{
+			name:  "Cursor up and back overwrite earlier cells",
+			input: "aaaa\nbbbb\x1b[A\x1b[2D\x1b[31mXX",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "", BG: "", T: "aa"},
+					{FG: "\x1b[31m", BG: "", T: "XX"},
+				},
+				{{FG: "", BG: "", T: "bbbb"}},
+			},
+		},
+		{
+			name:  "Absolute positioning",
+			input: "\x1b[2;3Hx\x1b[1;1Hy",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "y"}},
+				{{FG: "", BG: "", T: "  x"}},
+			},
+		},
+		{
+			name:  "Save and restore cursor",
+			input: "ab\x1b[scd\x1b[uXY",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "abXY"}},
+			},
+		},
+		{
+			name:  "Clear to end of line",
+			input: "abcdef\x1b[3D\x1b[K",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "abc   "}},
+			},
+		},
+		{
+			name:  "Clear screen homes the cursor",
+			input: "abc\ndef\x1b[2Jxy",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "xy"}},
+			},
+		},
+		{
+			name:  "Combined SGR codes and resets",
+			input: "\x1b[1;33;44mAB\x1b[0mC",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[33m", BG: "\x1b[44m", T: "AB"},
+					{FG: "\x1b[0m", BG: "", T: "C"},
+				},
+			},
+		},
+		{
+			name:  "Blink is kept as a modifier",
+			input: "\x1b[5;31mAB\x1b[25mC",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[5m\x1b[31m", BG: "", T: "AB"},
+					{FG: "\x1b[0m\x1b[31m", BG: "\x1b[49m", T: "C"},
+				},
+			},
+		},
+		{
+			name:  "Extended colours outside of 0-255 are ignored",
+			input: "\x1b[31m\x1b[38;5;300mA\x1b[1;256;0;0tB",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "AB"}},
+			},
+		},
+		{
+			name:  "Custom truecolor codes",
+			input: "\x1b[1;255;0;0t\x1b[0;0;0;255tX",
+			width: 80,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[38;2;255;0;0m", BG: "\x1b[48;2;0;0;255m", T: "X"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := screen.Render(tc.input, tc.width)
+
+			test.PrintANSITestResults(tc.input, tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
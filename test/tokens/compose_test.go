@@ -0,0 +1,119 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestCompose(t *testing.T) {
+	testCases := []struct {
+		name         string
+		base         [][]convert.ANSILineToken
+		overlay      [][]convert.ANSILineToken
+		x, y         int
+		transparency convert.Transparency
+		expected     [][]convert.ANSILineToken
+	}{
+		{
+			name: "Overlay replaces the cells below",
+			base: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "....."}},
+				{{FG: "", BG: "", T: "....."}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a b"}},
+			},
+			x: 1, y: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "....."}},
+				{{FG: "", BG: "", T: ".a b."}},
+			},
+		},
+		{
+			name: "Transparent characters show the cells below",
+			base: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "....."}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "\x1b[32m", BG: "", T: "a b"}},
+			},
+			x: 1, y: 0,
+			transparency: convert.Transparency{Chars: []rune{' '}},
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[31m", BG: "", T: "."}, {FG: "\x1b[32m", BG: "", T: "a"}, {FG: "\x1b[31m", BG: "", T: "."},
+					{FG: "\x1b[32m", BG: "", T: "b"}, {FG: "\x1b[31m", BG: "", T: "."},
+				},
+			},
+		},
+		{
+			name: "Transparent backgrounds show the cells below",
+			base: [][]convert.ANSILineToken{
+				{{FG: "", BG: "\x1b[44m", T: "...."}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "ab"}, {FG: "\x1b[31m", BG: "\x1b[42m", T: "cd"}},
+			},
+			x: 0, y: 0,
+			transparency: convert.Transparency{BGs: []int{0}},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "\x1b[44m", T: ".."}, {FG: "\x1b[31m", BG: "\x1b[42m", T: "cd"}},
+			},
+		},
+		{
+			name: "Result grows to fit the overlay",
+			base: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: ".."}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}},
+				{{FG: "\x1b[31m", BG: "", T: "cd"}},
+			},
+			x: 1, y: 0,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "."}, {FG: "\x1b[31m", BG: "", T: "ab"}},
+				{{FG: "", BG: "", T: " "}, {FG: "\x1b[31m", BG: "", T: "cd"}},
+			},
+		},
+		{
+			name: "Negative offsets cut off the overlay",
+			base: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "..."}},
+				{{FG: "", BG: "", T: "..."}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "ab"}},
+				{{FG: "", BG: "", T: "cd"}},
+			},
+			x: -1, y: -1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "d.."}},
+				{{FG: "", BG: "", T: "..."}},
+			},
+		},
+		{
+			name: "Half covered double-width runes become spaces",
+			base: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "中文"}},
+			},
+			overlay: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "ab"}},
+			},
+			x: 1, y: 0,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: " ab "}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.Compose(tc.base, tc.overlay, tc.x, tc.y, tc.transparency)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
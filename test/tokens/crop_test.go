@@ -0,0 +1,112 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestCrop(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      [][]convert.ANSILineToken
+		x, y, w, h int
+		expected   [][]convert.ANSILineToken
+	}{
+		{
+			name: "Crop plain text",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "abcdef"}},
+				{{FG: "", BG: "", T: "ghijkl"}},
+				{{FG: "", BG: "", T: "mnopqr"}},
+			},
+			x: 1, y: 1, w: 3, h: 2,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "hij"}},
+				{{FG: "", BG: "", T: "nop"}},
+			},
+		},
+		{
+			name: "Colours are kept at the left edge",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "abc"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "def"}},
+			},
+			x: 2, y: 0, w: 3, h: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "c"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "de"}},
+			},
+		},
+		{
+			name: "Colours set by earlier tokens are re-emitted",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}, {FG: "\x1b[31m", BG: "\x1b[44m", T: "cdef"}},
+			},
+			x: 4, y: 0, w: 2, h: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "ef"}},
+			},
+		},
+		{
+			name:  "Colours after a reset are the defaults",
+			input: convert.TokeniseANSIString("\x1b[31;44mab\x1b[0mcd\x1b[32mef"),
+			x:     3, y: 0, w: 3, h: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[0m", BG: "", T: "d"}, {FG: "\x1b[32m", BG: "", T: "ef"}},
+			},
+		},
+		{
+			name: "Short lines are padded",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "abc"}},
+				{},
+			},
+			x: 1, y: 0, w: 4, h: 3,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "bc"}, {FG: "\x1b[0m", BG: "", T: "  "}},
+				{{FG: "", BG: "", T: "    "}},
+				{{FG: "", BG: "", T: "    "}},
+			},
+		},
+		{
+			name: "Double-width runes cut by the edges become spaces",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a中b文c"}},
+			},
+			x: 2, y: 0, w: 3, h: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: " b "}},
+			},
+		},
+		{
+			name: "Double-width runes inside the edges are kept",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a中b文c"}},
+			},
+			x: 1, y: 0, w: 5, h: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "中b文"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := convert.Crop(tc.input, tc.x, tc.y, tc.w, tc.h)
+
+			test.Assert(nil, err, t)
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestCropInvalid(t *testing.T) {
+	input := [][]convert.ANSILineToken{{{FG: "", BG: "", T: "abc"}}}
+
+	_, err := convert.Crop(input, -1, 0, 1, 1)
+	test.Assert("invalid crop position -1,0, must not be negative", err.Error(), t)
+
+	_, err = convert.Crop(input, 0, 0, 0, 1)
+	test.Assert("invalid crop size 0x1, must be at least 1x1", err.Error(), t)
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestDOSTokenise(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		iceColour bool
+		expected  [][]convert.ANSILineToken
+	}{
+		{
+			name:  "Bold makes the foreground bright, before or after the colour",
+			input: "\x1b[1;31mab\x1b[32;1mcd\x1b[22mef",
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[91m", BG: "", T: "ab"},
+					{FG: "\x1b[92m", BG: "", T: "cd"},
+					{FG: "\x1b[32m", BG: "", T: "ef"},
+				},
+			},
+		},
+		{
+			name:  "Bold on its own applies to the current colour",
+			input: "\x1b[34mab\x1b[1mcd\x1b[0mef",
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[34m", BG: "", T: "ab"},
+					{FG: "\x1b[94m", BG: "", T: "cd"},
+					{FG: "\x1b[0m", BG: "", T: "ef"},
+				},
+			},
+		},
+		{
+			name:  "Bold default foreground is white",
+			input: "\x1b[0;1;44mab",
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[97m", BG: "\x1b[44m", T: "ab"}},
+			},
+		},
+		{
+			name:  "Bold brightens the first 8 of the 256 colours",
+			input: "\x1b[1mab\x1b[38;5;1mcd\x1b[38;5;129mef",
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[97m", BG: "", T: "ab"},
+					{FG: "\x1b[91m", BG: "", T: "cd"},
+					{FG: "\x1b[38;5;129m", BG: "", T: "ef"},
+				},
+			},
+		},
+		{
+			name:  "Bold carries over to the next line",
+			input: "\x1b[1;33mab\ncd",
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[93m", BG: "", T: "ab"}},
+				{{FG: "\x1b[93m", BG: "", T: "cd"}},
+			},
+		},
+		{
+			name:      "Blink with iCE colour is a bright background",
+			input:     "\x1b[5;47;30mab\x1b[25mcd",
+			iceColour: true,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[30m", BG: "\x1b[107m", T: "ab"},
+					{FG: "\x1b[30m", BG: "\x1b[47m", T: "cd"},
+				},
+			},
+		},
+		{
+			name:  "Blink without iCE colour",
+			input: "\x1b[5;47;30mab\x1b[25mcd",
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[5m\x1b[30m", BG: "\x1b[47m", T: "ab"},
+					{FG: "\x1b[30m", BG: "\x1b[47m", T: "cd"},
+				},
+			},
+		},
+		{
+			name:  "Truecolor is unchanged",
+			input: "\x1b[1m\x1b[1;224;224;224tab",
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1m\x1b[38;2;224;224;224m", BG: "", T: "ab"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.TokeniseDOSANSIString(tc.input, tc.iceColour)
+
+			test.PrintANSITestResults(tc.input, tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestBuildMinimalANSIString(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      [][]convert.ANSILineToken
+		padding    int
+		carryState bool
+		expected   string
+	}{
+		{
+			name: "Separate codes are combined",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1m\x1b[31m", BG: "\x1b[44m", T: "ab"}},
+			},
+			expected: "\x1b[1;31;44mab\x1b[0m\n",
+		},
+		{
+			name: "Only the changed colour is written",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "a"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "b"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "c"}},
+			},
+			expected: "\x1b[31;44ma\x1b[32mbc\x1b[0m\n",
+		},
+		{
+			name: "Redundant resets are removed",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[0m", BG: "", T: "a"}, {FG: "\x1b[0m\x1b[0;33m", BG: "", T: "b"}, {FG: "\x1b[0m", BG: "", T: ""}},
+				{{FG: "", BG: "", T: "c"}},
+			},
+			expected: "a\x1b[33mb\x1b[0m\nc\n",
+		},
+		{
+			name: "A reset is used when it is shorter",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1;5;31m", BG: "\x1b[44m", T: "a"}, {FG: "\x1b[0m", BG: "\x1b[43m", T: "b"}},
+			},
+			expected: "\x1b[1;5;31;44ma\x1b[0;43mb\x1b[0m\n",
+		},
+		{
+			name: "Turning off bold keeps dim",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1;2;38;5;208m", BG: "\x1b[48;5;17m", T: "a"}, {FG: "\x1b[22;2;38;5;208m", BG: "\x1b[48;5;17m", T: "b"}},
+			},
+			expected: "\x1b[1;2;38;5;208;48;5;17ma\x1b[22;2mb\x1b[0m\n",
+		},
+		{
+			name: "State is carried across lines",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "a"}},
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "b"}},
+				{{FG: "\x1b[31m", BG: "", T: "c"}},
+				{{FG: "", BG: "", T: "d"}},
+			},
+			carryState: true,
+			expected:   "\x1b[31;44ma\nb\n\x1b[49mc\n\x1b[0md\n",
+		},
+		{
+			name: "Carried state ends with a reset",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "a"}},
+				{{FG: "\x1b[31m", BG: "", T: "b"}},
+			},
+			carryState: true,
+			expected:   "\x1b[31ma\nb\x1b[0m\n",
+		},
+		{
+			name: "Padding is drawn without a carried background",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "a"}},
+				{{FG: "\x1b[31m", BG: "\x1b[44m", T: "b"}},
+			},
+			padding:    2,
+			carryState: true,
+			expected:   "  \x1b[31;44ma\n\x1b[49m  \x1b[44mb\x1b[0m\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.BuildMinimalANSIString(tc.input, tc.padding, tc.carryState)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
+
+func TestStyleDiff(t *testing.T) {
+	red := convert.Style{FG: convert.IndexedColour(1)}
+	boldRed := convert.Style{FG: convert.IndexedColour(1), Bold: true}
+
+	test.Assert("", red.Diff(red), t)
+	test.Assert("\x1b[1m", red.Diff(boldRed), t)
+	test.Assert("\x1b[22m", boldRed.Diff(red), t)
+	test.Assert("\x1b[0m", boldRed.Diff(convert.Style{}), t)
+	test.Assert("\x1b[48;2;1;2;3m", red.Diff(convert.Style{FG: red.FG, BG: convert.RGBColour(1, 2, 3)}), t)
+}
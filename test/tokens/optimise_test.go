@@ -127,6 +127,21 @@ func TestOptimiseANSITokens(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Optimise codes that select the same style",
+			input: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[31m", BG: "\x1b[44m", T: " "},
+					{FG: "\x1b[31;1m", BG: "\x1b[44m", T: " "},
+					{FG: "\x1b[1;31m", BG: "\x1b[48;5;4m", T: " "},
+				},
+			},
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[1m\x1b[31m", BG: "\x1b[44m", T: "   "},
+				},
+			},
+		},
 		{
 			name: "Optimise redundant resets",
 			input: [][]convert.ANSILineToken{
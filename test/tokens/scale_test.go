@@ -0,0 +1,129 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestScale(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [][]convert.ANSILineToken
+		factor   int
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name: "Scale by 1 is unchanged",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}},
+			},
+			factor: 1,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}},
+			},
+		},
+		{
+			name: "Scale by 2",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "▀"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "▄"}},
+			},
+			factor: 2,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "▀▀"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "▄▄"}},
+				{{FG: "\x1b[31m", BG: "", T: "▀▀"}, {FG: "\x1b[32m", BG: "\x1b[44m", T: "▄▄"}},
+			},
+		},
+		{
+			name: "Scale double-width runes by 3",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "a中"}},
+			},
+			factor: 3,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "aaa中中中"}},
+				{{FG: "", BG: "", T: "aaa中中中"}},
+				{{FG: "", BG: "", T: "aaa中中中"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := convert.Scale(tc.input, tc.factor)
+
+			test.Assert(nil, err, t)
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+
+	_, err := convert.Scale([][]convert.ANSILineToken{}, 0)
+	test.Assert("invalid scale 0, must be at least 1", err.Error(), t)
+}
+
+func TestDownscale(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [][]convert.ANSILineToken
+		mode     convert.DownscaleMode
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name: "Half blocks from 2 lines",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "\x1b[41m", T: "  "}, {FG: "", BG: "", T: "  "}},
+				{{FG: "", BG: "\x1b[44m", T: " "}, {FG: "", BG: "\x1b[41m", T: " "}, {FG: "", BG: "\x1b[44m", T: "  "}},
+			},
+			mode: convert.DownscaleHalfBlocks,
+			expected: [][]convert.ANSILineToken{
+				{
+					{FG: "\x1b[31m", BG: "\x1b[44m", T: "▀"}, {FG: "\x1b[39m", BG: "\x1b[41m", T: " "},
+					{FG: "\x1b[34m", BG: "\x1b[49m", T: "▄▄"},
+				},
+			},
+		},
+		{
+			name: "Quadrant blocks from 2x2 cells",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[32m", BG: "", T: "█"}, {FG: "", BG: "", T: " "}, {FG: "\x1b[32m", BG: "", T: "██"}},
+				{{FG: "", BG: "", T: "  "}, {FG: "\x1b[32m", BG: "", T: "█"}, {FG: "", BG: "", T: " "}},
+			},
+			mode: convert.DownscaleQuadrants,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[32m", BG: "", T: "▘▛"}},
+			},
+		},
+		{
+			name: "Half blocks count as their foreground, and bold makes it bright",
+			input: [][]convert.ANSILineToken{
+				{{FG: "\x1b[1m\x1b[33m", BG: "\x1b[44m", T: "▀"}, {FG: "\x1b[33m", BG: "\x1b[44m", T: "▘"}},
+			},
+			mode: convert.DownscaleHalfBlocks,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[93m", BG: "", T: "▀"}, {FG: "\x1b[34m", BG: "", T: "▀"}},
+			},
+		},
+		{
+			name: "The 2 most common colours are used, with others drawn as the nearest",
+			input: [][]convert.ANSILineToken{
+				{{FG: "", BG: "\x1b[41m", T: "  "}},
+				{{FG: "", BG: "\x1b[101m", T: " "}, {FG: "", BG: "\x1b[47m", T: " "}},
+			},
+			mode: convert.DownscaleQuadrants,
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "\x1b[101m", T: "▀"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.Downscale(tc.input, tc.mode)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}
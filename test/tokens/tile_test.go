@@ -0,0 +1,82 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tmck-code/go-ansi-convert/src/ansi-convert/convert"
+	"github.com/tmck-code/go-ansi-convert/test"
+)
+
+func TestTile(t *testing.T) {
+	red := [][]convert.ANSILineToken{
+		{{FG: "\x1b[31m", BG: "", T: "ab"}},
+		{{FG: "\x1b[31m", BG: "", T: "cd"}},
+	}
+	blue := [][]convert.ANSILineToken{
+		{{FG: "", BG: "\x1b[44m", T: "xyz"}},
+	}
+	plain := [][]convert.ANSILineToken{
+		{{FG: "", BG: "", T: "1"}},
+	}
+
+	testCases := []struct {
+		name     string
+		images   [][][]convert.ANSILineToken
+		opts     convert.TileOptions
+		expected [][]convert.ANSILineToken
+	}{
+		{
+			name:   "Row",
+			images: [][][]convert.ANSILineToken{red, blue},
+			opts:   convert.TileOptions{},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}, {FG: "\x1b[39m", BG: "\x1b[44m", T: "xyz"}},
+				{{FG: "\x1b[31m", BG: "", T: "cd"}, {FG: "\x1b[0m", BG: "", T: "   "}},
+			},
+		},
+		{
+			name:   "Row with a separator, padding and bottom alignment",
+			images: [][][]convert.ANSILineToken{red, plain},
+			opts:   convert.TileOptions{Padding: 1, Separator: "|", VAlign: convert.AlignEnd},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "\x1b[31m", BG: "", T: "ab"}, {FG: "\x1b[0m", BG: "", T: " |  "}},
+				{{FG: "\x1b[31m", BG: "", T: "cd"}, {FG: "\x1b[0m", BG: "", T: " | 1"}},
+			},
+		},
+		{
+			name:   "Column with a row separator and centre alignment",
+			images: [][][]convert.ANSILineToken{blue, plain},
+			opts:   convert.TileOptions{Columns: 1, RowSeparator: "-=", HAlign: convert.AlignCentre},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "\x1b[44m", T: "xyz"}},
+				{{FG: "", BG: "", T: "-=-"}},
+				{{FG: "", BG: "", T: " 1 "}},
+			},
+		},
+		{
+			name:   "Grid with an empty tile",
+			images: [][][]convert.ANSILineToken{plain, plain, plain},
+			opts:   convert.TileOptions{Columns: 2, Separator: "│", RowSeparator: "─", HAlign: convert.AlignEnd},
+			expected: [][]convert.ANSILineToken{
+				{{FG: "", BG: "", T: "1│1"}},
+				{{FG: "", BG: "", T: "───"}},
+				{{FG: "", BG: "", T: "1│ "}},
+			},
+		},
+		{
+			name:     "No images",
+			images:   [][][]convert.ANSILineToken{},
+			opts:     convert.TileOptions{},
+			expected: [][]convert.ANSILineToken{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convert.Tile(tc.images, tc.opts)
+
+			test.PrintANSITestResults("", tc.expected, result, t)
+			test.Assert(tc.expected, result, t)
+		})
+	}
+}